@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/api"
+	"github.com/jamie/prestigeleagueseasonfour/internal/calendar"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/render"
+)
+
+func main() {
+	// Konfiguration laden
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = config.DefaultPath
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Fehler beim Laden der Konfiguration: %v", err)
+	}
+
+	// Datenbank öffnen
+	db, err := database.New(cfg.Database.DSN)
+	if err != nil {
+		log.Fatalf("Fehler beim Öffnen der Datenbank: %v", err)
+	}
+	defer db.Close()
+
+	// Kalender-Feeds verwenden Zeitzone und Saisonstart der Liga
+	location, err := cfg.League.Location()
+	if err != nil {
+		log.Fatalf("Fehler in der Konfiguration: %v", err)
+	}
+	seasonStart, err := cfg.League.SeasonStartDate()
+	if err != nil {
+		log.Fatalf("Fehler in der Konfiguration: %v", err)
+	}
+
+	// Grafiken für Social Media (ohne Assets deaktiviert)
+	renderer, err := render.New(cfg.League.AssetsDir)
+	if err != nil {
+		log.Printf("Grafiken deaktiviert: %v", err)
+	} else {
+		renderer.LeagueName = cfg.League.Name
+	}
+
+	handler := api.NewServer(db, api.Options{
+		Renderer:    renderer,
+		CORSOrigins: cfg.API.CORSOrigins,
+		Calendar: &calendar.Calendar{
+			LeagueName:  cfg.League.Name,
+			Location:    location,
+			SeasonStart: seasonStart,
+		},
+	})
+
+	server := &http.Server{
+		Addr:              cfg.API.Listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		fmt.Printf("API läuft auf %s%s\n", cfg.API.Listen, api.Prefix)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Fehler beim Starten des API-Servers: %v", err)
+		}
+	}()
+
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	<-sc
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Fehler beim Beenden des API-Servers: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -9,17 +10,31 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/bot"
+	"github.com/jamie/prestigeleagueseasonfour/internal/commands"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/render"
+	"github.com/jamie/prestigeleagueseasonfour/internal/webhooks"
 )
 
 func main() {
+	// Konfiguration laden
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = config.DefaultPath
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Fehler beim Laden der Konfiguration: %v", err)
+	}
+
 	token := os.Getenv("DISCORD_BOT_TOKEN")
 	if token == "" {
 		log.Fatal("DISCORD_BOT_TOKEN Umgebungsvariable nicht gesetzt")
 	}
 
 	// Datenbank öffnen
-	db, err := database.New("data/league.db")
+	db, err := database.New(cfg.Database.DSN)
 	if err != nil {
 		log.Fatalf("Fehler beim Öffnen der Datenbank: %v", err)
 	}
@@ -34,9 +49,34 @@ func main() {
 
 	discord.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsGuilds
 
+	// Tabellen und Ergebnisse zusätzlich als Grafik posten
+	if renderer, err := render.New(cfg.League.AssetsDir); err != nil {
+		log.Printf("Grafiken deaktiviert: %v", err)
+	} else {
+		renderer.LeagueName = cfg.League.Name
+		commands.SetRenderer(renderer)
+	}
+
 	bot.SetDatabase(db)
+	bot.SetConfig(cfg)
 	bot.RegisterHandlers(discord)
 
+	// Webhooks: Events werden in der Datenbank eingereiht und im Hintergrund zugestellt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if len(cfg.Webhooks.Endpoints) > 0 {
+		publisher := webhooks.NewPublisher(db, cfg.Webhooks)
+		db.SetWebhookRouter(publisher)
+		go publisher.Run(ctx)
+		fmt.Printf("Webhooks aktiv für %d Empfänger\n", len(cfg.Webhooks.Endpoints))
+	}
+
+	// Automatische Sicherungen der SQLite-Datenbank
+	if cfg.Backup.Interval > 0 && db.Dialect() == database.SQLite {
+		go bot.RunBackups(ctx)
+		fmt.Printf("Automatische Sicherung alle %s nach %s\n", cfg.Backup.Interval, cfg.Backup.Dir)
+	}
+
 	err = discord.Open()
 	if err != nil {
 		log.Fatalf("Fehler beim Öffnen der Verbindung: %v", err)
@@ -1,19 +1,65 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
 )
 
 func main() {
+	// Konfiguration laden
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = config.DefaultPath
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Fehler beim Laden der Konfiguration: %v", err)
+	}
+
+	if len(os.Args) > 1 {
+		ctx := context.Background()
+		switch os.Args[1] {
+		case "copy-sqlite-to-postgres":
+			if err := copySQLiteToPostgres(ctx, cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Fehler beim Kopieren der Datenbank: %v", err)
+			}
+			return
+		case "backup":
+			if err := backupDatabase(ctx, cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Fehler beim Sichern der Datenbank: %v", err)
+			}
+			return
+		case "restore":
+			if err := restoreDatabase(ctx, cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Fehler beim Wiederherstellen der Datenbank: %v", err)
+			}
+			return
+		case "export":
+			if err := exportLeague(ctx, cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Fehler beim Exportieren der Liga: %v", err)
+			}
+			return
+		case "import":
+			if err := importLeague(ctx, cfg, os.Args[2:]); err != nil {
+				log.Fatalf("Fehler beim Importieren der Liga: %v", err)
+			}
+			return
+		}
+	}
+
 	// Datenbank öffnen
-	db, err := database.New("data/league.db")
+	db, err := database.New(cfg.Database.DSN)
 	if err != nil {
 		log.Fatalf("Fehler beim Öffnen der Datenbank: %v", err)
 	}
@@ -30,14 +76,210 @@ func main() {
 		}
 		fmt.Printf("%d Rollen geladen\n", len(roles))
 
-		if err := importTeamsFromCSV(db, "Data/teams.csv", roles); err != nil {
+		if err := importTeamsFromCSV(context.Background(), db, "Data/teams.csv", roles); err != nil {
 			log.Fatalf("Fehler beim Importieren der Teams: %v", err)
 		}
 		fmt.Println("Teams erfolgreich importiert!")
 	}
 }
 
-func importTeamsFromCSV(db *database.Database, filePath string, roles map[string]string) error {
+// copySQLiteToPostgres kopiert eine SQLite-Datenbank in eine leere PostgreSQL-Datenbank.
+// Aufruf: migrate copy-sqlite-to-postgres [sqlite-datei] [postgres-dsn]
+// Ohne Angaben wird data/league.db in die PostgreSQL-Datenbank aus database.dsn kopiert.
+func copySQLiteToPostgres(ctx context.Context, cfg *config.Config, args []string) error {
+	source, target := "data/league.db", cfg.Database.DSN
+	if len(args) > 0 {
+		source = args[0]
+	}
+	if len(args) > 1 {
+		target = args[1]
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		return fmt.Errorf("sqlite-datenbank %s nicht gefunden: %w", source, err)
+	}
+
+	src, err := database.New(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if src.Dialect() != database.SQLite {
+		return fmt.Errorf("quelle %s ist keine sqlite-datenbank", source)
+	}
+
+	dst, err := database.New(target)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	if dst.Dialect() != database.Postgres {
+		return fmt.Errorf("ziel ist keine postgresql-datenbank, bitte DSN angeben oder database.dsn setzen")
+	}
+
+	copied, err := src.CopyTo(ctx, dst)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range database.CopyTables() {
+		fmt.Printf("%-22s %6d Zeilen\n", table, copied[table])
+	}
+	fmt.Println("Datenbank erfolgreich nach PostgreSQL kopiert!")
+	return nil
+}
+
+// backupDatabase sichert die SQLite-Datenbank aus database.dsn im laufenden Betrieb.
+// Aufruf: migrate backup [verzeichnis]
+// Ohne Angabe landet die Sicherung in backup.dir, danach bleiben nur die backup.keep neuesten erhalten.
+func backupDatabase(ctx context.Context, cfg *config.Config, args []string) error {
+	dir := cfg.Backup.Dir
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	db, err := database.New(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	path, err := db.CreateBackup(ctx, dir, cfg.Backup.Keep)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sicherung erstellt: %s\n", path)
+	return nil
+}
+
+// restoreDatabase ersetzt die SQLite-Datenbank aus database.dsn durch eine Sicherung.
+// Aufruf: migrate restore [sicherung]
+// Ohne Angabe wird die neueste Sicherung aus backup.dir verwendet. Der bisherige Stand wird
+// vorher selbst gesichert. Bot und API müssen währenddessen gestoppt sein.
+func restoreDatabase(ctx context.Context, cfg *config.Config, args []string) error {
+	var source string
+	if len(args) > 0 {
+		source = args[0]
+	} else {
+		backups, err := database.ListBackups(cfg.Backup.Dir)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return fmt.Errorf("keine sicherung in %s gefunden", cfg.Backup.Dir)
+		}
+		source = backups[len(backups)-1]
+	}
+
+	db, err := database.New(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Außerhalb der Rotation, damit die Sicherung des bisherigen Stands nie automatisch gelöscht wird
+	if err := os.MkdirAll(cfg.Backup.Dir, 0o755); err != nil {
+		return fmt.Errorf("fehler beim Anlegen des Sicherungsverzeichnisses: %w", err)
+	}
+	previous := filepath.Join(cfg.Backup.Dir, "vor-restore-"+time.Now().UTC().Format("20060102-150405")+".db")
+	if err := db.Backup(ctx, previous); err != nil {
+		return fmt.Errorf("fehler beim Sichern des bisherigen Stands: %w", err)
+	}
+	fmt.Printf("Bisheriger Stand gesichert: %s\n", previous)
+
+	if err := db.Restore(ctx, source); err != nil {
+		return err
+	}
+
+	fmt.Printf("Datenbank aus %s wiederhergestellt!\n", source)
+	return nil
+}
+
+// exportLeague schreibt Teams, Roster, Matches, Ergebnisse und Channels als JSON.
+// Aufruf: migrate export [datei]
+// Ohne Angabe oder mit - landet der Export auf der Standardausgabe.
+func exportLeague(ctx context.Context, cfg *config.Config, args []string) error {
+	db, err := database.New(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	exp, err := db.Export(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(exp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fehler beim Kodieren des Exports: %w", err)
+	}
+	data = append(data, '\n')
+
+	if len(args) == 0 || args[0] == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(args[0], data, 0o644); err != nil {
+		return fmt.Errorf("fehler beim Schreiben des Exports: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d Teams und %d Matches nach %s exportiert\n", len(exp.Teams), len(exp.Matches), args[0])
+	return nil
+}
+
+// importLeague spielt einen Export in die leere Datenbank aus database.dsn ein, z.B. um Staging
+// mit dem Stand aus Produktion zu füllen. IDs werden neu vergeben.
+// Aufruf: migrate import <datei> [--without-channels]
+// Mit --without-channels werden Discord-Channels und Nachrichten nicht übernommen.
+func importLeague(ctx context.Context, cfg *config.Config, args []string) error {
+	var (
+		source string
+		opts   database.ImportOptions
+	)
+	for _, arg := range args {
+		switch {
+		case arg == "--without-channels":
+			opts.WithoutChannels = true
+		case strings.HasPrefix(arg, "--"):
+			return fmt.Errorf("unbekannte option %s", arg)
+		default:
+			source = arg
+		}
+	}
+	if source == "" {
+		return fmt.Errorf("aufruf: migrate import <datei> [--without-channels]")
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("fehler beim Lesen des Exports: %w", err)
+	}
+	var exp database.LeagueExport
+	if err := json.Unmarshal(data, &exp); err != nil {
+		return fmt.Errorf("export %s ist kein gültiges json: %w", source, err)
+	}
+
+	db, err := database.New(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	imported, err := db.Import(ctx, &exp, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range []string{"teams", "players", "matches", "disqualifications", "standings", "recaps"} {
+		fmt.Printf("%-18s %6d\n", kind, imported[kind])
+	}
+	fmt.Printf("Export vom %s erfolgreich importiert!\n", exp.ExportedAt.Local().Format("02.01.2006 15:04"))
+	return nil
+}
+
+func importTeamsFromCSV(ctx context.Context, db database.TeamRepository, filePath string, roles map[string]string) error {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("fehler beim Öffnen der CSV Datei: %w", err)
@@ -76,30 +318,54 @@ func importTeamsFromCSV(db *database.Database, filePath string, roles map[string
 			continue
 		}
 
+		// Roster aus den Spalten "Spieler N" / "Tracker SN"
+		var players []*database.Player
+		for col := 1; col+1 < len(record)-1; col += 2 {
+			playerName := strings.TrimSpace(record[col])
+			if playerName == "" {
+				continue
+			}
+			players = append(players, &database.Player{
+				Name:       playerName,
+				TrackerURL: strings.TrimSpace(record[col+1]),
+			})
+		}
+
 		// Team erstellen
-		team, err := db.CreateTeam(teamName, division)
+		team, err := db.CreateTeam(ctx, teamName, division)
 		if err != nil {
-			// Wenn Team bereits existiert, überspringen
-			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-				log.Printf("Team '%s' existiert bereits, überspringe...", teamName)
+			// Wenn Team bereits existiert, nur das Roster aktualisieren
+			if database.IsDuplicate(err) {
+				log.Printf("Team '%s' existiert bereits, aktualisiere Roster...", teamName)
+				existing, err := db.GetTeamByName(ctx, teamName)
+				if err != nil {
+					return fmt.Errorf("fehler beim Abrufen des Teams '%s': %w", teamName, err)
+				}
+				if err := db.ReplacePlayers(ctx, existing.ID, players); err != nil {
+					log.Printf("Warnung: Fehler beim Speichern des Rosters für Team '%s': %v", teamName, err)
+				}
 				continue
 			}
 			return fmt.Errorf("fehler beim Erstellen des Teams '%s': %w", teamName, err)
 		}
 
+		if err := db.ReplacePlayers(ctx, team.ID, players); err != nil {
+			log.Printf("Warnung: Fehler beim Speichern des Rosters für Team '%s': %v", teamName, err)
+		}
+
 		// Rollen-ID setzen, falls vorhanden
 		if roleID, exists := roles[teamName]; exists {
-			if err := db.UpdateTeamRoleID(team.ID, roleID); err != nil {
+			if err := db.UpdateTeamRoleID(ctx, team.ID, roleID); err != nil {
 				log.Printf("Warnung: Fehler beim Setzen der Rollen-ID für Team '%s': %v", teamName, err)
 			} else {
-				fmt.Printf("[%d/%d] Team erstellt: ID=%d, Name=%s, Division=%d, RoleID=%s\n",
-					i+1, len(records), team.ID, team.Name, team.Division, roleID)
+				fmt.Printf("[%d/%d] Team erstellt: ID=%d, Name=%s, Division=%d, Spieler=%d, RoleID=%s\n",
+					i+1, len(records), team.ID, team.Name, team.Division, len(players), roleID)
 				continue
 			}
 		}
 
-		fmt.Printf("[%d/%d] Team erstellt: ID=%d, Name=%s, Division=%d\n",
-			i+1, len(records), team.ID, team.Name, team.Division)
+		fmt.Printf("[%d/%d] Team erstellt: ID=%d, Name=%s, Division=%d, Spieler=%d\n",
+			i+1, len(records), team.ID, team.Name, team.Division, len(players))
 	}
 
 	return nil
@@ -0,0 +1,547 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/api"
+	"github.com/jamie/prestigeleagueseasonfour/internal/calendar"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/render"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+// league enthält die IDs der Testdaten
+type league struct {
+	teams       map[string]int
+	matchID     int // Woche 1, Ergebnis 4:2
+	scheduledID int // Woche 2, Termin vereinbart
+}
+
+// newTestServer erstellt eine Testdatenbank mit zwei Divisionen und startet die API darauf
+func newTestServer(t *testing.T) (*httptest.Server, league) {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := database.New(filepath.Join(t.TempDir(), "league.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	l := league{teams: make(map[string]int)}
+	for _, team := range []struct {
+		name     string
+		division int
+	}{
+		{"Alpha", 1}, {"Bravo", 1}, {"Charlie", 1}, {"Delta", 2}, {"Echo", 2},
+	} {
+		created, err := db.CreateTeam(ctx, team.name, team.division)
+		if err != nil {
+			t.Fatalf("CreateTeam(%s): %v", team.name, err)
+		}
+		l.teams[team.name] = created.ID
+	}
+
+	id := func(name string) *int {
+		v := l.teams[name]
+		return &v
+	}
+
+	// Division 1 hat drei Teams, also ein Freilos pro Spieltag
+	err = db.ReplaceMatches(ctx, 1, 1, []database.MatchPlan{
+		{Matchday: 1, TeamHomeID: l.teams["Alpha"], TeamAwayID: id("Bravo")},
+		{Matchday: 1, TeamHomeID: l.teams["Charlie"]},
+		{Matchday: 2, TeamHomeID: l.teams["Bravo"], TeamAwayID: id("Charlie")},
+		{Matchday: 2, TeamHomeID: l.teams["Alpha"]},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceMatches: %v", err)
+	}
+	err = db.ReplaceMatches(ctx, 2, 1, []database.MatchPlan{
+		{Matchday: 1, TeamHomeID: l.teams["Delta"], TeamAwayID: id("Echo")},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceMatches: %v", err)
+	}
+
+	matches, err := db.GetMatchesByDivisionAndMatchday(ctx, 1, 1)
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("GetMatchesByDivisionAndMatchday: %v", err)
+	}
+	l.matchID = matches[0].ID
+	if err := db.UpdateMatchScore(ctx, l.matchID, matches[0].Version, 4, 2, "test"); err != nil {
+		t.Fatalf("UpdateMatchScore: %v", err)
+	}
+
+	err = db.ReplacePlayers(ctx, l.teams["Alpha"], []*database.Player{
+		{Name: "Spieler Eins", TrackerURL: "https://rocketleague.tracker.network/rocket-league/profile/epic/eins"},
+		{Name: "Spieler Zwei"},
+	})
+	if err != nil {
+		t.Fatalf("ReplacePlayers: %v", err)
+	}
+
+	// Das Match in Woche 2 hat einen vereinbarten Termin
+	matches, err = db.GetMatchesByDivisionAndMatchday(ctx, 1, 2)
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("GetMatchesByDivisionAndMatchday: %v", err)
+	}
+	l.scheduledID = matches[0].ID
+	scheduledAt := time.Date(2026, 10, 14, 18, 30, 0, 0, time.UTC)
+	if err := db.SetMatchTime(ctx, l.scheduledID, &scheduledAt); err != nil {
+		t.Fatalf("SetMatchTime: %v", err)
+	}
+
+	renderer, err := render.New(filepath.Join("..", "..", "web", "static"))
+	if err != nil {
+		t.Fatalf("render.New: %v", err)
+	}
+
+	srv := httptest.NewServer(api.NewServer(db, api.Options{
+		CORSOrigins: []string{"*"},
+		Renderer:    renderer,
+		Calendar: &calendar.Calendar{
+			LeagueName:  "Prestige League",
+			Location:    time.UTC,
+			SeasonStart: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		},
+	}))
+	t.Cleanup(srv.Close)
+	return srv, l
+}
+
+// TestContract prüft, dass alle Antworten der API dem OpenAPI-Dokument entsprechen
+func TestContract(t *testing.T) {
+	srv, l := newTestServer(t)
+	spec := loadSpec(t)
+
+	cases := []struct {
+		path   string // Pfad-Template aus dem OpenAPI-Dokument
+		url    string
+		status int
+	}{
+		{"/divisions", "/divisions", http.StatusOK},
+		{"/divisions/{division}/teams", "/divisions/1/teams", http.StatusOK},
+		{"/divisions/{division}/teams", "/divisions/9/teams", http.StatusNotFound},
+		{"/divisions/{division}/teams", "/divisions/eins/teams", http.StatusBadRequest},
+		{"/divisions/{division}/standings", "/divisions/1/standings", http.StatusOK},
+		{"/divisions/{division}/standings", "/divisions/9/standings", http.StatusNotFound},
+		{"/divisions/{division}/matches", "/divisions/1/matches?per_page=2&page=2", http.StatusOK},
+		{"/divisions/{division}/matches", "/divisions/1/matches?per_page=500", http.StatusBadRequest},
+		{"/teams", "/teams", http.StatusOK},
+		{"/teams", "/teams?division=2", http.StatusOK},
+		{"/teams", "/teams?division=x", http.StatusBadRequest},
+		{"/teams/{id}", fmt.Sprintf("/teams/%d", l.teams["Alpha"]), http.StatusOK},
+		{"/teams/{id}", "/teams/9999", http.StatusNotFound},
+		{"/teams/{id}/roster", fmt.Sprintf("/teams/%d/roster", l.teams["Alpha"]), http.StatusOK},
+		{"/teams/{id}/roster", fmt.Sprintf("/teams/%d/roster", l.teams["Echo"]), http.StatusOK},
+		{"/matches", "/matches", http.StatusOK},
+		{"/matches", "/matches?division=1&matchday=2", http.StatusOK},
+		{"/matches", fmt.Sprintf("/matches?team=%d", l.teams["Charlie"]), http.StatusOK},
+		{"/matches", "/matches?page=0", http.StatusBadRequest},
+		{"/matches/{id}", fmt.Sprintf("/matches/%d", l.matchID), http.StatusOK},
+		{"/matches/{id}", "/matches/9999", http.StatusNotFound},
+		{"/divisions/{division}/calendar.ics", "/divisions/1/calendar.ics", http.StatusOK},
+		{"/divisions/{division}/calendar.ics", "/divisions/9/calendar.ics", http.StatusNotFound},
+		{"/teams/{id}/calendar.ics", fmt.Sprintf("/teams/%d/calendar.ics", l.teams["Bravo"]), http.StatusOK},
+		{"/teams/{id}/calendar.ics", "/teams/9999/calendar.ics", http.StatusNotFound},
+		{"/divisions/{division}/standings.png", "/divisions/1/standings.png", http.StatusOK},
+		{"/divisions/{division}/standings.png", "/divisions/9/standings.png", http.StatusNotFound},
+		{"/divisions/{division}/matchdays/{matchday}/results.png", "/divisions/1/matchdays/1/results.png", http.StatusOK},
+		{"/divisions/{division}/matchdays/{matchday}/results.png", "/divisions/1/matchdays/99/results.png", http.StatusNotFound},
+		{"/divisions/{division}/matchdays/{matchday}/results.png", "/divisions/1/matchdays/x/results.png", http.StatusBadRequest},
+	}
+
+	covered := make(map[string]bool)
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + api.Prefix + tc.url)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.status)
+			}
+
+			mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+			schema := spec.responseSchema(t, tc.path, resp.StatusCode, mediaType)
+
+			if mediaType == "text/calendar" {
+				body, _ := io.ReadAll(resp.Body)
+				if !strings.HasPrefix(string(body), "BEGIN:VCALENDAR\r\n") {
+					t.Fatalf("body is not an iCalendar feed: %.40q", body)
+				}
+				return
+			}
+			if mediaType == "image/png" {
+				img, err := png.Decode(resp.Body)
+				if err != nil {
+					t.Fatalf("body is not a PNG image: %v", err)
+				}
+				if img.Bounds().Dx() != render.Width {
+					t.Errorf("image width = %d, want %d", img.Bounds().Dx(), render.Width)
+				}
+				return
+			}
+
+			var body any
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			for _, problem := range spec.validate(schema, body, "$") {
+				t.Error(problem)
+			}
+		})
+		if tc.status == http.StatusOK {
+			covered[tc.path] = true
+		}
+	}
+
+	// Jeder dokumentierte Pfad muss mindestens einmal erfolgreich geprüft werden
+	for path := range spec.mapAt(t, "paths") {
+		if !covered[path] {
+			t.Errorf("path %s is documented but not covered by the contract test", path)
+		}
+	}
+}
+
+// TestConditionalRequests prüft die dokumentierten 304-Antworten
+func TestConditionalRequests(t *testing.T) {
+	srv, _ := newTestServer(t)
+	url := srv.URL + api.Prefix + "/divisions/1/standings"
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("missing cache headers: ETag=%q Last-Modified=%q", etag, lastModified)
+	}
+
+	for header, value := range map[string]string{"If-None-Match": etag, "If-Modified-Since": lastModified} {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set(header, value)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("%s: status = %d, want 304", header, resp.StatusCode)
+		}
+	}
+}
+
+// TestSpecServed prüft, dass der Server das eingebettete Dokument ausliefert
+func TestSpecServed(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + api.Prefix + "/openapi.yaml")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != string(leagueapi.Spec) {
+		t.Fatalf("status = %d, spec served = %v", resp.StatusCode, string(body) == string(leagueapi.Spec))
+	}
+}
+
+// TestClient prüft den Go-Client gegen den echten Server
+func TestClient(t *testing.T) {
+	srv, l := newTestServer(t)
+	client := leagueapi.NewClient(srv.URL + api.Prefix)
+	ctx := context.Background()
+
+	divisions, err := client.Divisions(ctx)
+	if err != nil || len(divisions) != 2 || divisions[0].TeamCount != 3 {
+		t.Fatalf("Divisions = %+v, %v", divisions, err)
+	}
+
+	teams, err := client.DivisionTeams(ctx, 2)
+	if err != nil || len(teams) != 2 {
+		t.Fatalf("DivisionTeams = %+v, %v", teams, err)
+	}
+
+	rows, err := client.Standings(ctx, 1)
+	if err != nil || len(rows) != 3 || rows[0].Name != "Alpha" || rows[0].Points != 3 {
+		t.Fatalf("Standings = %+v, %v", rows, err)
+	}
+
+	page, err := client.DivisionMatches(ctx, 1, leagueapi.ListOptions{PerPage: 3})
+	if err != nil || len(page.Data) != 3 || page.Pagination.Total != 4 {
+		t.Fatalf("DivisionMatches = %+v, %v", page, err)
+	}
+
+	teamPage, err := client.Teams(ctx, leagueapi.TeamFilter{Division: 1})
+	if err != nil || teamPage.Pagination.Total != 3 {
+		t.Fatalf("Teams = %+v, %v", teamPage, err)
+	}
+
+	detail, err := client.Team(ctx, l.teams["Alpha"])
+	if err != nil || len(detail.Roster) != 2 || detail.Standing == nil || len(detail.Matches) != 2 {
+		t.Fatalf("Team = %+v, %v", detail, err)
+	}
+
+	roster, err := client.Roster(ctx, l.teams["Alpha"])
+	if err != nil || roster[0].TrackerURL == "" || roster[1].TrackerURL != "" {
+		t.Fatalf("Roster = %+v, %v", roster, err)
+	}
+
+	matchPage, err := client.Matches(ctx, leagueapi.MatchFilter{Division: 1, Matchday: 1})
+	if err != nil || len(matchPage.Data) != 2 {
+		t.Fatalf("Matches = %+v, %v", matchPage, err)
+	}
+
+	match, err := client.Match(ctx, l.matchID)
+	if err != nil || !match.Completed || *match.ScoreHome != 4 || match.ReportedAt == nil {
+		t.Fatalf("Match = %+v, %v", match, err)
+	}
+
+	scheduled, err := client.Match(ctx, l.scheduledID)
+	if err != nil || scheduled.ScheduledAt == nil || scheduled.ScheduledAt.Hour() != 18 {
+		t.Fatalf("Match = %+v, %v", scheduled, err)
+	}
+
+	_, err = client.Team(ctx, 9999)
+	apiErr, ok := err.(*leagueapi.APIError)
+	if !ok || apiErr.StatusCode != http.StatusNotFound || apiErr.Message == "" {
+		t.Fatalf("Team(9999) error = %v", err)
+	}
+}
+
+// TestCalendar prüft Inhalt und Format der iCalendar-Feeds
+func TestCalendar(t *testing.T) {
+	srv, l := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + api.Prefix + fmt.Sprintf("/teams/%d/calendar.ics", l.teams["Alpha"]))
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	feed := string(raw)
+
+	for _, line := range strings.Split(strings.TrimSuffix(feed, "\r\n"), "\r\n") {
+		if len(line) > 75 || strings.Contains(line, "\n") {
+			t.Errorf("invalid content line %q", line)
+		}
+	}
+
+	// Zeilen zusammenführen, um Inhalte unabhängig von der Faltung zu prüfen
+	unfolded := strings.ReplaceAll(feed, "\r\n ", "")
+	for _, want := range []string{
+		"X-WR-CALNAME:Prestige League – Alpha",
+		fmt.Sprintf("UID:match-%d@prestigeleague.de", l.matchID),
+		"SUMMARY:Woche 1: Alpha 4:2 Bravo",
+		"DESCRIPTION:Division 1\\, Woche 1\\nGegner: Bravo\\nErgebnis: 4:2",
+		"DTSTART;VALUE=DATE:20261005",
+		"DTEND;VALUE=DATE:20261012",
+		"SUMMARY:Woche 2: Alpha – Spielfrei",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("feed does not contain %q", want)
+		}
+	}
+	if n := strings.Count(unfolded, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("feed has %d events, want 2", n)
+	}
+
+	resp, err = http.Get(srv.URL + api.Prefix + "/divisions/1/calendar.ics")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ = io.ReadAll(resp.Body)
+	unfolded = strings.ReplaceAll(string(raw), "\r\n ", "")
+
+	for _, want := range []string{
+		fmt.Sprintf("UID:match-%d@prestigeleague.de", l.scheduledID),
+		"DTSTART:20261014T183000Z",
+		"DTEND:20261014T203000Z",
+		"SUMMARY:Woche 2: Bravo vs Charlie",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("division feed does not contain %q", want)
+		}
+	}
+}
+
+// openAPI ist ein minimaler Validator für die im Dokument verwendeten Schema-Features
+type openAPI struct {
+	doc map[string]any
+}
+
+func loadSpec(t *testing.T) *openAPI {
+	t.Helper()
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(leagueapi.Spec, &doc); err != nil {
+		t.Fatalf("parse openapi.yaml: %v", err)
+	}
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		t.Fatalf("openapi version = %q", version)
+	}
+	return &openAPI{doc: doc}
+}
+
+// mapAt liefert das Objekt unter einem durch "/" getrennten Pfad
+func (o *openAPI) mapAt(t *testing.T, path string) map[string]any {
+	t.Helper()
+
+	current := o.doc
+	for _, key := range strings.Split(path, "/") {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			t.Fatalf("openapi.yaml: %s not found", path)
+		}
+		current = next
+	}
+	return current
+}
+
+// resolve folgt einer lokalen $ref-Referenz
+func (o *openAPI) resolve(node map[string]any) map[string]any {
+	for {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		current := o.doc
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			current, _ = current[key].(map[string]any)
+		}
+		node = current
+	}
+}
+
+// responseSchema liefert das Schema einer dokumentierten Antwort
+func (o *openAPI) responseSchema(t *testing.T, path string, status int, mediaType string) map[string]any {
+	t.Helper()
+
+	item, _ := o.mapAt(t, "paths")[path].(map[string]any)
+	operation, ok := item["get"].(map[string]any)
+	if !ok {
+		t.Fatalf("GET %s is not documented", path)
+	}
+	responses, _ := operation["responses"].(map[string]any)
+	response, ok := responses[fmt.Sprint(status)].(map[string]any)
+	if !ok {
+		t.Fatalf("status %d is not documented for GET %s", status, path)
+	}
+	response = o.resolve(response)
+
+	content, _ := response["content"].(map[string]any)
+	media, _ := content[mediaType].(map[string]any)
+	schema, ok := media["schema"].(map[string]any)
+	if !ok {
+		t.Fatalf("GET %s %d has no %s schema", path, status, mediaType)
+	}
+	return schema
+}
+
+// validate prüft einen dekodierten JSON-Wert gegen ein Schema und liefert alle Abweichungen
+func (o *openAPI) validate(schema map[string]any, value any, at string) []string {
+	schema = o.resolve(schema)
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return []string{at + ": null is not allowed"}
+	}
+
+	var problems []string
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			if subSchema, ok := sub.(map[string]any); ok {
+				problems = append(problems, o.validate(subSchema, value, at)...)
+			}
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected object, got %T", at, value))
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %q", at, name))
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := properties[key].(map[string]any)
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					problems = append(problems, fmt.Sprintf("%s: undocumented property %q", at, key))
+				}
+				continue
+			}
+			problems = append(problems, o.validate(property, object[key], at+"."+key)...)
+		}
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected array, got %T", at, value))
+		}
+		itemSchema, _ := schema["items"].(map[string]any)
+		for i, item := range items {
+			problems = append(problems, o.validate(itemSchema, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			problems = append(problems, fmt.Sprintf("%s: expected integer, got %v", at, value))
+		}
+
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected string, got %T", at, value))
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, text); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid date-time %q", at, text))
+			}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected boolean, got %T", at, value))
+		}
+	}
+
+	return problems
+}
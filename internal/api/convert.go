@@ -0,0 +1,77 @@
+package api
+
+import (
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+func newTeam(t *database.Team) leagueapi.Team {
+	return leagueapi.Team{
+		ID:             t.ID,
+		Name:           t.Name,
+		Division:       t.Division,
+		IsDisqualified: t.IsDisqualified,
+		IsWithdrawn:    t.IsWithdrawn,
+	}
+}
+
+func newPlayer(p *database.Player) leagueapi.Player {
+	return leagueapi.Player{
+		Name:       p.Name,
+		TrackerURL: p.TrackerURL,
+	}
+}
+
+func newMatch(m *database.Match, teamNames map[int]string) leagueapi.Match {
+	match := leagueapi.Match{
+		ID:        m.ID,
+		Division:  m.Division,
+		Matchday:  m.Matchday,
+		IsBye:     m.IsBye(),
+		Completed: m.IsPlayed(),
+	}
+
+	home, homeName := m.TeamHomeID, teamNames[m.TeamHomeID]
+	match.HomeTeamID, match.HomeTeam = &home, &homeName
+	if m.TeamAwayID.Valid {
+		id := int(m.TeamAwayID.Int64)
+		name := teamNames[id]
+		match.AwayTeamID, match.AwayTeam = &id, &name
+	}
+	if m.ScoreHome.Valid {
+		score := int(m.ScoreHome.Int64)
+		match.ScoreHome = &score
+	}
+	if m.ScoreAway.Valid {
+		score := int(m.ScoreAway.Int64)
+		match.ScoreAway = &score
+	}
+	if m.ReportedAt.Valid {
+		reportedAt := m.ReportedAt.Time.UTC()
+		match.ReportedAt = &reportedAt
+	}
+	if m.ScheduledAt.Valid {
+		scheduledAt := m.ScheduledAt.Time.UTC()
+		match.ScheduledAt = &scheduledAt
+	}
+
+	return match
+}
+
+func newStanding(r *standings.Row) leagueapi.Standing {
+	return leagueapi.Standing{
+		Position:       r.Position,
+		TeamID:         r.TeamID,
+		Name:           r.Name,
+		IsDisqualified: r.IsDisqualified,
+		IsWithdrawn:    r.IsWithdrawn,
+		Played:         r.Played,
+		Wins:           r.Wins,
+		Losses:         r.Losses,
+		GamesWon:       r.GamesWon,
+		GamesLost:      r.GamesLost,
+		GameDiff:       r.GameDiff(),
+		Points:         r.Points,
+	}
+}
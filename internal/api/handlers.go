@@ -0,0 +1,394 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/calendar"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+// handleDivisions liefert alle Divisionen: GET /divisions
+func (s *Server) handleDivisions(r *http.Request) (any, error) {
+	ctx := r.Context()
+	teams, err := s.db.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	divisions := []leagueapi.Division{}
+	for _, team := range teams {
+		if counts[team.Division] == 0 {
+			divisions = append(divisions, leagueapi.Division{Division: team.Division})
+		}
+		counts[team.Division]++
+	}
+
+	for i := range divisions {
+		divisions[i].TeamCount = counts[divisions[i].Division]
+	}
+
+	return divisions, nil
+}
+
+// handleDivision liefert Teams, Tabelle, Matches, Kalender oder Grafiken einer Division:
+// GET /divisions/{division}/teams, /standings, /matches, /calendar.ics, /standings.png,
+// /matchdays/{matchday}/results.png
+func (s *Server) handleDivision(r *http.Request) (any, error) {
+	ctx := r.Context()
+	parts := pathParts(r, Prefix+"/divisions")
+	if len(parts) == 4 && parts[1] == "matchdays" && parts[3] == "results.png" {
+		return s.resultsImage(ctx, parts[0], parts[2])
+	}
+	if len(parts) != 2 {
+		return nil, notFound("not found")
+	}
+
+	division, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, badRequest("division must be an integer")
+	}
+
+	switch parts[1] {
+	case "teams":
+		teams, err := s.db.GetTeamsByDivision(ctx, division)
+		if err != nil {
+			return nil, err
+		}
+		if len(teams) == 0 {
+			return nil, notFound(fmt.Sprintf("division %d not found", division))
+		}
+		result := make([]leagueapi.Team, 0, len(teams))
+		for _, team := range teams {
+			result = append(result, newTeam(team))
+		}
+		return result, nil
+
+	case "standings":
+		rows, err := standings.ForDivision(ctx, s.db, division)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, notFound(fmt.Sprintf("division %d not found", division))
+		}
+		result := make([]leagueapi.Standing, 0, len(rows))
+		for _, row := range rows {
+			result = append(result, newStanding(row))
+		}
+		return result, nil
+
+	case "matches":
+		matches, err := s.db.GetMatchesByDivision(ctx, division)
+		if err != nil {
+			return nil, err
+		}
+		return s.matchPage(r, matches)
+
+	case "standings.png":
+		if s.renderer == nil {
+			return nil, notFound("images are not available")
+		}
+		rows, err := standings.ForDivision(ctx, s.db, division)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, notFound(fmt.Sprintf("division %d not found", division))
+		}
+		stamp, err := s.db.LastModified()
+		if err != nil {
+			return nil, err
+		}
+		png, err := s.renderer.Standings(division, rows, stamp)
+		if err != nil {
+			return nil, err
+		}
+		return &document{contentType: "image/png", body: png}, nil
+
+	case "calendar.ics":
+		matches, err := s.db.GetMatchesByDivision(ctx, division)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, notFound(fmt.Sprintf("division %d has no schedule", division))
+		}
+		return s.calendarFeed(ctx, fmt.Sprintf("Division %d", division), matches, 0)
+	}
+
+	return nil, notFound("not found")
+}
+
+// handleTeams liefert alle Teams, optional gefiltert nach Division: GET /teams?division=
+func (s *Server) handleTeams(r *http.Request) (any, error) {
+	ctx := r.Context()
+	division, err := intParam(r, "division", 0)
+	if err != nil {
+		return nil, badRequest("division must be an integer")
+	}
+
+	var teams []*database.Team
+	if division > 0 {
+		teams, err = s.db.GetTeamsByDivision(ctx, division)
+	} else {
+		teams, err = s.db.GetAllTeams(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]leagueapi.Team, 0, len(teams))
+	for _, team := range teams {
+		result = append(result, newTeam(team))
+	}
+
+	return paginate(r, result)
+}
+
+// handleTeam liefert die Details, das Roster oder den Kalender eines Teams:
+// GET /teams/{id}, /teams/{id}/roster, /teams/{id}/calendar.ics
+func (s *Server) handleTeam(r *http.Request) (any, error) {
+	ctx := r.Context()
+	parts := pathParts(r, Prefix+"/teams")
+	if len(parts) == 0 || len(parts) > 2 {
+		return nil, notFound("not found")
+	}
+
+	teamID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, badRequest("team id must be an integer")
+	}
+
+	team, err := s.db.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, notFound(fmt.Sprintf("team %d not found", teamID))
+	}
+
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "roster":
+			return s.roster(ctx, team.ID)
+		case "calendar.ics":
+			matches, err := s.db.GetMatchesByTeam(ctx, team.ID)
+			if err != nil {
+				return nil, err
+			}
+			return s.calendarFeed(ctx, team.Name, matches, team.ID)
+		}
+		return nil, notFound("not found")
+	}
+
+	roster, err := s.roster(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := leagueapi.TeamDetail{
+		Team:    newTeam(team),
+		Roster:  roster,
+		Matches: []leagueapi.Match{},
+	}
+
+	rows, err := standings.ForDivision(ctx, s.db, team.Division)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.TeamID == team.ID {
+			standing := newStanding(row)
+			detail.Standing = &standing
+		}
+	}
+
+	matches, err := s.db.GetMatchesByTeam(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	teamNames, err := s.teamNames(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		detail.Matches = append(detail.Matches, newMatch(match, teamNames))
+	}
+
+	return detail, nil
+}
+
+// handleMatches liefert Matches, optional gefiltert: GET /matches?division=&matchday=&team=
+func (s *Server) handleMatches(r *http.Request) (any, error) {
+	ctx := r.Context()
+	division, err := intParam(r, "division", 0)
+	if err != nil {
+		return nil, badRequest("division must be an integer")
+	}
+	matchday, err := intParam(r, "matchday", 0)
+	if err != nil {
+		return nil, badRequest("matchday must be an integer")
+	}
+	teamID, err := intParam(r, "team", 0)
+	if err != nil {
+		return nil, badRequest("team must be an integer")
+	}
+
+	var matches []*database.Match
+	switch {
+	case teamID > 0:
+		matches, err = s.db.GetMatchesByTeam(ctx, teamID)
+	case division > 0:
+		matches, err = s.db.GetMatchesByDivision(ctx, division)
+	default:
+		matches, err = s.db.GetAllMatches(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*database.Match
+	for _, match := range matches {
+		if division > 0 && match.Division != division {
+			continue
+		}
+		if matchday > 0 && match.Matchday != matchday {
+			continue
+		}
+		filtered = append(filtered, match)
+	}
+
+	return s.matchPage(r, filtered)
+}
+
+// handleMatch liefert ein einzelnes Match: GET /matches/{id}
+func (s *Server) handleMatch(r *http.Request) (any, error) {
+	ctx := r.Context()
+	parts := pathParts(r, Prefix+"/matches")
+	if len(parts) != 1 {
+		return nil, notFound("not found")
+	}
+
+	matchID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, badRequest("match id must be an integer")
+	}
+
+	match, err := s.db.GetMatchByID(ctx, matchID)
+	if err != nil {
+		return nil, notFound(fmt.Sprintf("match %d not found", matchID))
+	}
+
+	teamNames, err := s.teamNames(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return newMatch(match, teamNames), nil
+}
+
+// resultsImage zeichnet die Ergebnisse eines Spieltags: GET /divisions/{division}/matchdays/{matchday}/results.png
+func (s *Server) resultsImage(ctx context.Context, divisionParam, matchdayParam string) (any, error) {
+	division, err := strconv.Atoi(divisionParam)
+	if err != nil {
+		return nil, badRequest("division must be an integer")
+	}
+	matchday, err := strconv.Atoi(matchdayParam)
+	if err != nil {
+		return nil, badRequest("matchday must be an integer")
+	}
+	if s.renderer == nil {
+		return nil, notFound("images are not available")
+	}
+
+	matches, err := s.db.GetMatchesByDivisionAndMatchday(ctx, division, matchday)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, notFound(fmt.Sprintf("division %d has no matchday %d", division, matchday))
+	}
+	teamNames, err := s.teamNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := s.renderer.MatchdayResults(division, matchday, matches, teamNames)
+	if err != nil {
+		return nil, err
+	}
+	return &document{contentType: "image/png", body: png}, nil
+}
+
+// roster gibt die Spieler eines Teams in ihrer JSON-Darstellung zurück
+func (s *Server) roster(ctx context.Context, teamID int) ([]leagueapi.Player, error) {
+	players, err := s.db.GetPlayersByTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := make([]leagueapi.Player, 0, len(players))
+	for _, player := range players {
+		roster = append(roster, newPlayer(player))
+	}
+	return roster, nil
+}
+
+// calendarFeed erzeugt einen iCalendar-Feed aus den Matches
+func (s *Server) calendarFeed(ctx context.Context, name string, matches []*database.Match, teamID int) (any, error) {
+	teams, err := s.db.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*database.Team, len(teams))
+	for _, team := range teams {
+		byID[team.ID] = team
+	}
+
+	stamp, err := s.db.LastModified()
+	if err != nil {
+		return nil, err
+	}
+
+	body := s.calendar.Render(calendar.Feed{
+		Name:    name,
+		Matches: matches,
+		Teams:   byID,
+		TeamID:  teamID,
+		Stamp:   stamp,
+	})
+
+	return &document{contentType: "text/calendar; charset=utf-8", body: body}, nil
+}
+
+// matchPage wandelt Matches in ihre JSON-Darstellung um und paginiert sie
+func (s *Server) matchPage(r *http.Request, matches []*database.Match) (any, error) {
+	teamNames, err := s.teamNames(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]leagueapi.Match, 0, len(matches))
+	for _, match := range matches {
+		result = append(result, newMatch(match, teamNames))
+	}
+
+	return paginate(r, result)
+}
+
+// teamNames gibt eine Zuordnung von Team-ID zu Teamname zurück
+func (s *Server) teamNames(ctx context.Context) (map[int]string, error) {
+	teams, err := s.db.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+	return names, nil
+}
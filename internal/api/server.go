@@ -0,0 +1,283 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/calendar"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/render"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+// Prefix ist der Pfad-Präfix aller API-Endpoints
+const Prefix = "/api/v1"
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+// Server liefert die Liga-Daten als JSON aus
+type Server struct {
+	db          *database.Database
+	calendar    *calendar.Calendar
+	renderer    *render.Renderer
+	corsOrigins map[string]bool
+	corsAll     bool
+	mux         *http.ServeMux
+}
+
+// Options enthält die Einstellungen des API-Servers
+type Options struct {
+	// CORSOrigins enthält die erlaubten Origins ("*" = alle)
+	CORSOrigins []string
+	// Calendar erzeugt die iCalendar-Feeds. Ohne Calendar oder SeasonStart fehlen Matches ohne Termin.
+	Calendar *calendar.Calendar
+	// Renderer zeichnet Tabellen und Ergebnisse als PNG (nil = Grafik-Endpoints liefern 404)
+	Renderer *render.Renderer
+}
+
+// NewServer erstellt einen API-Server
+func NewServer(db *database.Database, opts Options) *Server {
+	s := &Server{
+		db:          db,
+		calendar:    opts.Calendar,
+		renderer:    opts.Renderer,
+		corsOrigins: make(map[string]bool),
+		mux:         http.NewServeMux(),
+	}
+
+	if s.calendar == nil {
+		s.calendar = &calendar.Calendar{}
+	}
+
+	for _, origin := range opts.CORSOrigins {
+		if origin == "*" {
+			s.corsAll = true
+		}
+		s.corsOrigins[strings.TrimRight(origin, "/")] = true
+	}
+
+	s.mux.HandleFunc(Prefix+"/divisions", s.endpoint(s.handleDivisions))
+	s.mux.HandleFunc(Prefix+"/divisions/", s.endpoint(s.handleDivision))
+	s.mux.HandleFunc(Prefix+"/teams", s.endpoint(s.handleTeams))
+	s.mux.HandleFunc(Prefix+"/teams/", s.endpoint(s.handleTeam))
+	s.mux.HandleFunc(Prefix+"/matches", s.endpoint(s.handleMatches))
+	s.mux.HandleFunc(Prefix+"/matches/", s.endpoint(s.handleMatch))
+	s.mux.HandleFunc(Prefix+"/openapi.yaml", serveSpec)
+
+	return s
+}
+
+// ServeHTTP setzt CORS-Header und leitet an die Endpoints weiter
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && (s.corsAll || s.corsOrigins[origin]) {
+		if s.corsAll {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "If-None-Match, If-Modified-Since")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified")
+	}
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// serveSpec liefert das OpenAPI-Dokument der API aus
+func serveSpec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		writeJSON(w, http.StatusMethodNotAllowed, leagueapi.Error{Error: "method not allowed"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(leagueapi.Spec)
+	}
+}
+
+// apiError ist ein Fehler mit HTTP-Statuscode
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func notFound(message string) error {
+	return &apiError{status: http.StatusNotFound, message: message}
+}
+
+func badRequest(message string) error {
+	return &apiError{status: http.StatusBadRequest, message: message}
+}
+
+// document ist eine Antwort, die nicht als JSON ausgeliefert wird
+type document struct {
+	contentType string
+	body        []byte
+}
+
+// endpoint macht aus einer Handler-Funktion einen HTTP-Handler mit JSON-Ausgabe,
+// ETag und Last-Modified Caching. Gibt die Funktion ein *document zurück, wird
+// dieses unverändert ausgeliefert.
+func (s *Server) endpoint(fn func(r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			writeJSON(w, http.StatusMethodNotAllowed, leagueapi.Error{Error: "method not allowed"})
+			return
+		}
+
+		lastModified, err := s.db.LastModified()
+		if err != nil {
+			log.Printf("[API] %v", err)
+			writeJSON(w, http.StatusInternalServerError, leagueapi.Error{Error: "internal server error"})
+			return
+		}
+		lastModified = lastModified.Truncate(time.Second)
+
+		// Ohne ETag-Prüfung reicht der Zeitstempel für eine 304-Antwort
+		if r.Header.Get("If-None-Match") == "" && !lastModified.IsZero() {
+			if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(since) {
+				w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		value, err := fn(r)
+		if err != nil {
+			var apiErr *apiError
+			if errors.As(err, &apiErr) {
+				writeJSON(w, apiErr.status, leagueapi.Error{Error: apiErr.message})
+				return
+			}
+			log.Printf("[API] %s: %v", r.URL.Path, err)
+			writeJSON(w, http.StatusInternalServerError, leagueapi.Error{Error: "internal server error"})
+			return
+		}
+
+		contentType := "application/json; charset=utf-8"
+		var body []byte
+		if doc, ok := value.(*document); ok {
+			contentType, body = doc.contentType, doc.body
+		} else if body, err = json.Marshal(value); err != nil {
+			log.Printf("[API] %s: %v", r.URL.Path, err)
+			writeJSON(w, http.StatusInternalServerError, leagueapi.Error{Error: "internal server error"})
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=0, must-revalidate")
+		if !lastModified.IsZero() {
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		}
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	}
+}
+
+// etagMatches prüft einen If-None-Match Header gegen den aktuellen ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// paginate schneidet eine Liste anhand der Query-Parameter page und per_page zu
+func paginate[T any](r *http.Request, items []T) (*leagueapi.Page[T], error) {
+	page, err := intParam(r, "page", 1)
+	if err != nil || page < 1 {
+		return nil, badRequest("page must be a positive integer")
+	}
+
+	perPage, err := intParam(r, "per_page", defaultPerPage)
+	if err != nil || perPage < 1 || perPage > maxPerPage {
+		return nil, badRequest("per_page must be between 1 and " + strconv.Itoa(maxPerPage))
+	}
+
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+
+	data := items[start:end]
+	if data == nil {
+		data = []T{}
+	}
+
+	return &leagueapi.Page[T]{
+		Data: data,
+		Pagination: leagueapi.Pagination{
+			Page:    page,
+			PerPage: perPage,
+			Total:   len(items),
+		},
+	}, nil
+}
+
+// intParam liest einen optionalen ganzzahligen Query-Parameter
+func intParam(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// pathParts zerlegt den Pfad nach dem angegebenen Präfix in seine Segmente
+func pathParts(r *http.Request, prefix string) []string {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if rest == "" {
+		return nil
+	}
+	return strings.Split(rest, "/")
+}
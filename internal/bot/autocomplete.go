@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// maxChoices ist die maximale Anzahl an Vorschlägen, die Discord annimmt
+const maxChoices = 25
+
+// autocomplete beantwortet Autocomplete-Anfragen für Team-Namen (Option "name") und Match-IDs (Option "match").
+// Die Berechtigung für den Command prüft bereits der Router.
+func autocomplete(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	focused, division := autocompleteOptions(data.Options)
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	var err error
+	switch {
+	case focused == nil:
+	case focused.Name == "name":
+		choices, err = teamChoices(ctx, focused.StringValue(), division)
+	case focused.Name == "match":
+		choices, err = matchChoices(ctx, fmt.Sprint(focused.Value), division)
+	}
+	if err != nil {
+		log.Printf("[Autocomplete] /%s: %v", data.Name, err)
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+// autocompleteOptions gibt die gerade bearbeitete Option und die bereits gewählte Division zurück (0 = keine)
+func autocompleteOptions(options []*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.ApplicationCommandInteractionDataOption, int) {
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	division := 0
+
+	for _, opt := range options {
+		switch {
+		case opt.Focused:
+			focused = opt
+		case opt.Name == "division":
+			// Während der Eingabe kann der Wert auch noch ein String sein
+			switch value := opt.Value.(type) {
+			case float64:
+				division = int(value)
+			case string:
+				division, _ = strconv.Atoi(value)
+			}
+		}
+	}
+
+	return focused, division
+}
+
+// teamChoices schlägt Teams vor, deren Name mit der Eingabe beginnt (danach: die Eingabe enthält)
+func teamChoices(ctx context.Context, input string, division int) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	teams, err := db.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input = strings.ToLower(strings.TrimSpace(input))
+	var prefix, contains []*database.Team
+	for _, team := range teams {
+		if division != 0 && team.Division != division {
+			continue
+		}
+		name := strings.ToLower(team.Name)
+		switch {
+		case strings.HasPrefix(name, input):
+			prefix = append(prefix, team)
+		case strings.Contains(name, input):
+			contains = append(contains, team)
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, team := range append(prefix, contains...) {
+		if len(choices) == maxChoices {
+			break
+		}
+		label := fmt.Sprintf("%s (Div %d)", team.Name, team.Division)
+		switch {
+		case team.IsDisqualified:
+			label += " – DQ"
+		case team.IsWithdrawn:
+			label += " – zurückgezogen"
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateChoice(label),
+			Value: team.Name,
+		})
+	}
+
+	return choices, nil
+}
+
+// matchChoices schlägt Matches vor. Zahlen werden als Anfang der Match-ID gesucht,
+// Text in den Teamnamen. Offene Matches erscheinen vor bereits gespielten.
+func matchChoices(ctx context.Context, input string, division int) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	var (
+		matches []*database.Match
+		err     error
+	)
+	if division != 0 {
+		matches, err = db.GetMatchesByDivision(ctx, division)
+	} else {
+		matches, err = db.GetAllMatches(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := db.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+
+	input = strings.ToLower(strings.TrimSpace(input))
+	_, err = strconv.Atoi(input)
+	byID := err == nil
+
+	var found []*database.Match
+	for _, match := range matches {
+		if match.IsBye() {
+			continue
+		}
+		if byID {
+			if !strings.HasPrefix(strconv.Itoa(match.ID), input) {
+				continue
+			}
+		} else if input != "" {
+			home := strings.ToLower(names[match.TeamHomeID])
+			away := strings.ToLower(names[int(match.TeamAwayID.Int64)])
+			if !strings.Contains(home, input) && !strings.Contains(away, input) {
+				continue
+			}
+		}
+		found = append(found, match)
+	}
+
+	sort.SliceStable(found, func(a, b int) bool {
+		return !found[a].IsPlayed() && found[b].IsPlayed()
+	})
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, match := range found {
+		if len(choices) == maxChoices {
+			break
+		}
+		label := fmt.Sprintf("%s vs %s (Div %d, Week %d)",
+			names[match.TeamHomeID], names[int(match.TeamAwayID.Int64)], match.Division, match.Matchday)
+		if match.IsPlayed() {
+			label += fmt.Sprintf(" – %d:%d", match.ScoreHome.Int64, match.ScoreAway.Int64)
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateChoice(fmt.Sprintf("#%d %s", match.ID, label)),
+			Value: match.ID,
+		})
+	}
+
+	return choices, nil
+}
+
+// truncateChoice kürzt einen Vorschlag auf die von Discord erlaubten 100 Zeichen
+func truncateChoice(label string) string {
+	runes := []rune(label)
+	if len(runes) <= 100 {
+		return label
+	}
+	return string(runes[:99]) + "…"
+}
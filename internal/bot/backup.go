@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// backupTimeout begrenzt eine automatische Sicherung
+const backupTimeout = 5 * time.Minute
+
+// RunBackups legt alle backup.interval eine Sicherung der Datenbank an, bis ctx abgebrochen wird.
+// Ältere Sicherungen werden dabei bis auf backup.keep gelöscht.
+func RunBackups(ctx context.Context) {
+	ticker := time.NewTicker(cfg.Backup.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			backupCtx, cancel := context.WithTimeout(ctx, backupTimeout)
+			path, err := db.CreateBackup(backupCtx, cfg.Backup.Dir, cfg.Backup.Keep)
+			cancel()
+			if err != nil {
+				log.Printf("[Backup] Automatische Sicherung fehlgeschlagen: %v", err)
+				continue
+			}
+			log.Printf("[Backup] Sicherung erstellt: %s", path)
+		}
+	}
+}
@@ -1,22 +1,70 @@
 package bot
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/commands"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/internal/permissions"
 )
 
 var db *database.Database
 
+var cfg = config.Default()
+
+var policy = permissions.NewPolicy(cfg)
+
+// standingsBoard pflegt die Live-Tabellen (nil = kein Tabellen-Channel konfiguriert)
+var standingsBoard *standingsUpdater
+
+// results postet Ergebnisse und Spieltag-Rückblicke (nil = keine Ergebnis-Channels konfiguriert)
+var results *resultsFeed
+
 func SetDatabase(database *database.Database) {
 	db = database
 }
 
+// SetConfig übernimmt die Konfiguration und baut daraus die Berechtigungs-Policy
+func SetConfig(c *config.Config) {
+	cfg = c
+	policy = permissions.NewPolicy(c)
+}
+
 func RegisterHandlers(s *discordgo.Session) {
 	s.AddHandler(messageCreate)
 	s.AddHandler(ready)
-	s.AddHandler(interactionCreate)
-	s.AddHandler(modalSubmit)
+
+	routes := newRoutes()
+	s.AddHandler(routes.handle)
+	log.Printf("[Router] %s registriert", routes)
+
+	if cfg.Channels.Standings != "" {
+		standingsBoard = newStandingsUpdater(s, cfg.Channels.Standings)
+	}
+	if len(cfg.Channels.Results) > 0 {
+		results = newResultsFeed(s, cfg.Channels.Results)
+	}
+
+	// Jede Änderung am Liga-Zustand ins Audit-Log spiegeln, betroffene Tabellen aktualisieren
+	// und neue Ergebnisse in die Ergebnis-Channels posten
+	if db != nil && (cfg.Channels.AuditLog != "" || standingsBoard != nil || results != nil) {
+		db.SetAuditHook(func(entry *database.AuditEntry) {
+			if cfg.Channels.AuditLog != "" {
+				commands.MirrorAuditEntry(s, cfg.Channels.AuditLog, entry)
+			}
+			if standingsBoard != nil {
+				standingsBoard.schedule(entry.Divisions()...)
+			}
+			if results != nil {
+				results.enqueue(entry)
+			}
+		})
+	}
 }
 
 func ready(s *discordgo.Session, event *discordgo.Ready) {
@@ -24,6 +72,11 @@ func ready(s *discordgo.Session, event *discordgo.Ready) {
 
 	// Slash Commands registrieren
 	registerCommands(s)
+
+	// Live-Tabellen nach einem (Neu-)Start prüfen und ggf. neu erstellen
+	if standingsBoard != nil {
+		standingsBoard.scheduleAll()
+	}
 }
 
 func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
@@ -34,157 +87,63 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Hier können Commands hinzugefügt werden
 }
 
-func registerCommands(s *discordgo.Session) {
-	commands := []*discordgo.ApplicationCommand{
-		{
-			Name:                     "schedule",
-			Description:              "Erstellt einen Spielplan für eine Division",
-			DefaultMemberPermissions: &[]int64{discordgo.PermissionAdministrator}[0],
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "division",
-					Description: "Die Division für den Spielplan",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:                     "createchannels",
-			Description:              "Erstellt Discord Channels für alle Matches einer Division und eines Spieltags",
-			DefaultMemberPermissions: &[]int64{discordgo.PermissionAdministrator}[0],
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "division",
-					Description: "Die Division",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "matchday",
-					Description: "Der Spieltag für den die Channels erstellt werden",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "category",
-					Description: "Die Kategorie-ID unter der die Channels erstellt werden",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:        "report_result",
-			Description: "Trägt das Ergebnis eines Matches ein (nur in Match-Channels)",
-		},
-		{
-			Name:                     "disqualify",
-			Description:              "Disqualifiziert ein Team (alle Matches werden mit 0:3 gewertet)",
-			DefaultMemberPermissions: &[]int64{discordgo.PermissionAdministrator}[0],
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionRole,
-					Name:        "team",
-					Description: "Die Team-Rolle des zu disqualifizierenden Teams",
-					Required:    true,
-				},
-			},
-		},
-		{
-			Name:                     "requalify",
-			Description:              "Hebt die Disqualifikation eines Teams auf",
-			DefaultMemberPermissions: &[]int64{discordgo.PermissionAdministrator}[0],
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionRole,
-					Name:        "team",
-					Description: "Die Team-Rolle des Teams",
-					Required:    true,
-				},
-			},
-		},
-	}
-
-	for _, cmd := range commands {
-		_, err := s.ApplicationCommandCreate(s.State.User.ID, "", cmd)
-		if err != nil {
-			panic(err)
-		}
+// leagueLocation gibt die Zeitzone der Liga zurück (UTC bei ungültiger Konfiguration)
+func leagueLocation() *time.Location {
+	loc, err := cfg.League.Location()
+	if err != nil {
+		log.Printf("[Config] %v", err)
+		return time.UTC
 	}
+	return loc
 }
 
-// hasAdminPermission prüft ob der User Administrator-Rechte hat
-func hasAdminPermission(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
-	// Spezielle User-ID die immer berechtigt ist
-	if i.Member != nil && i.Member.User.ID == "423480294948208661" {
-		return true
-	}
-
-	// Guild Member Permissions prüfen
-	member := i.Member
-	if member == nil {
-		return false
-	}
-
-	// Prüfe ob der User Administrator-Rechte hat
-	permissions := member.Permissions
-	return (permissions & discordgo.PermissionAdministrator) != 0
+// hasPermission prüft anhand der Staff-Stufen, ob der User den Command ausführen darf
+func hasPermission(i *discordgo.InteractionCreate, command string) bool {
+	return policy.Allowed(i.Member, command)
 }
 
-// respondError sendet eine Fehlermeldung als Antwort
-func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
+// newRoutes registriert alle Commands, Autocompletes, Modals und Buttons.
+// Jeder Handler läuft mit Panic-Schutz, Latenz-Log und Berechtigungsprüfung.
+func newRoutes() *router {
+	rt := newRouter(recoverPanics, logLatency, requirePermission)
+
+	rt.command("schedule", withDB(commands.ScheduleCommand))
+	rt.command("createchannels", withDB(commands.CreateChannelsCommand))
+	rt.command("report_result", withDB(commands.ReportResultCommand))
+	rt.command("match_time", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		return commands.MatchTimeCommand(ctx, s, i, db, leagueLocation())
+	})
+	rt.command("disqualify", withDB(commands.DisqualifyCommand))
+	rt.command("requalify", withDB(commands.RequalifyCommand))
+	rt.command("withdraw", withDB(commands.WithdrawCommand))
+	rt.command("late_entry", withDB(commands.LateEntryCommand))
+	rt.command("set_result", withDB(commands.SetResultCommand))
+	rt.command("clear_result", withDB(commands.ClearResultCommand))
+	rt.command("audit", withDB(commands.AuditCommand))
+	rt.command("standings", withDB(commands.StandingsCommand))
+	rt.command("team", withDB(commands.TeamCommand))
+	rt.command("matches", withDB(commands.MatchesCommand))
+	rt.command("backup", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		return commands.BackupCommand(ctx, s, i, db, commands.BackupOptions{
+			Dir:       cfg.Backup.Dir,
+			Keep:      cfg.Backup.Keep,
+			ChannelID: cfg.Channels.Admin,
+		})
 	})
-}
 
-func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.Type != discordgo.InteractionApplicationCommand {
-		return
+	for _, name := range autocompleteCommands(commandDefinitions()) {
+		rt.autocomplete(name, autocomplete)
 	}
 
-	switch i.ApplicationCommandData().Name {
-	case "schedule":
-		if !hasAdminPermission(s, i) {
-			respondError(s, i, "❌ Dieser Command kann nur von Administratoren ausgeführt werden.")
-			return
-		}
-		commands.ScheduleCommand(s, i, db)
-	case "createchannels":
-		if !hasAdminPermission(s, i) {
-			respondError(s, i, "❌ Dieser Command kann nur von Administratoren ausgeführt werden.")
-			return
-		}
-		commands.CreateChannelsCommand(s, i, db)
-	case "report_result":
-		commands.ReportResultCommand(s, i, db)
-	case "disqualify":
-		if !hasAdminPermission(s, i) {
-			respondError(s, i, "❌ Dieser Command kann nur von Administratoren ausgeführt werden.")
-			return
-		}
-		commands.DisqualifyCommand(s, i, db)
-	case "requalify":
-		if !hasAdminPermission(s, i) {
-			respondError(s, i, "❌ Dieser Command kann nur von Administratoren ausgeführt werden.")
-			return
-		}
-		commands.RequalifyCommand(s, i, db)
-	}
-}
+	rt.modal(commands.ReportResultModalPrefix, "report_result", withDB(commands.HandleReportResultModal))
+	rt.button(commands.MatchesButtonPrefix, "matches", withDB(commands.HandleMatchesPage))
 
-func modalSubmit(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.Type != discordgo.InteractionModalSubmit {
-		return
-	}
+	return rt
+}
 
-	// Report Result Modal
-	if len(i.ModalSubmitData().CustomID) > 14 && i.ModalSubmitData().CustomID[:14] == "report_result:" {
-		commands.HandleReportResultModal(s, i, db)
+// withDB macht aus einem Handler des commands-Pakets einen handlerFunc
+func withDB(h func(context.Context, discord.Session, *discordgo.InteractionCreate, database.Store) error) handlerFunc {
+	return func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		return h(ctx, s, i, db)
 	}
 }
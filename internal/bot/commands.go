@@ -0,0 +1,329 @@
+package bot
+
+import "github.com/bwmarrin/discordgo"
+
+// commandDefinitions gibt alle Slash Commands des Bots inklusive Standard-Berechtigungen zurück.
+// Die Liste ist der Sollzustand: registerCommands gleicht die bei Discord registrierten Commands damit ab.
+func commandDefinitions() []*discordgo.ApplicationCommand {
+	commands := []*discordgo.ApplicationCommand{
+		{
+			Name:        "schedule",
+			Description: "Erstellt einen Spielplan für eine Division",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "division",
+					Description: "Die Division für den Spielplan",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "mode",
+					Description: "Alles neu erstellen oder nur noch nicht begonnene Spieltage",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Kompletter Spielplan", Value: "full"},
+						{Name: "Nur verbleibende Spieltage", Value: "remaining"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "force",
+					Description: "Auch Matches mit Ergebnis oder Channel überschreiben",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "dry_run",
+					Description: "Nur anzeigen, was gelöscht und erstellt würde",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "createchannels",
+			Description: "Erstellt Discord Channels für alle Matches einer Division und eines Spieltags",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "division",
+					Description: "Die Division",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "matchday",
+					Description: "Der Spieltag für den die Channels erstellt werden",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "category",
+					Description: "Die Kategorie-ID unter der die Channels erstellt werden",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "report_result",
+			Description: "Trägt das Ergebnis eines Matches ein (nur in Match-Channels)",
+		},
+		{
+			Name:        "match_time",
+			Description: "Trägt den vereinbarten Spieltermin ein (nur in Match-Channels)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "time",
+					Description: "Termin im Format TT.MM.JJJJ HH:MM (leer lassen zum Entfernen)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "disqualify",
+			Description: "Disqualifiziert ein Team (alle Matches werden mit 0:3 gewertet)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "team",
+					Description: "Die Team-Rolle des zu disqualifizierenden Teams",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "Der Teamname (alternativ zur Rolle, mit Vorschlägen)",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "requalify",
+			Description: "Hebt die Disqualifikation eines Teams auf",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "team",
+					Description: "Die Team-Rolle des Teams",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "Der Teamname (alternativ zur Rolle, mit Vorschlägen)",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "restore_matches",
+					Description: "Durch die Disqualifikation gewertete Matches auf den vorherigen Stand zurücksetzen",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "withdraw",
+			Description: "Zieht ein Team zurück (offene Matches werden Free Wins für die Gegner)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "team",
+					Description: "Die Team-Rolle des zurückgezogenen Teams",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "Der Teamname (alternativ zur Rolle, mit Vorschlägen)",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "late_entry",
+			Description: "Setzt ein nachgemeldetes Team in den freien Platz des Spielplans ein",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "Der Teamname",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "division",
+					Description: "Die Division",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "Die Team-Rolle",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "from_matchday",
+					Description: "Ab welchem Spieltag das Team spielt (Standard: erster Spieltag ohne Ergebnis)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "set_result",
+			Description: "Setzt oder korrigiert das Ergebnis eines Matches",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Name:         "match",
+					Description:  "Die Match-ID (Vorschläge nach Teamname oder ID)",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "home",
+					Description: "Siege des Heimteams",
+					Required:    true,
+					MinValue:    &[]float64{0}[0],
+					MaxValue:    4,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "away",
+					Description: "Siege des Auswärtsteams",
+					Required:    true,
+					MinValue:    &[]float64{0}[0],
+					MaxValue:    4,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reason",
+					Description: "Begründung für die Änderung",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "clear_result",
+			Description: "Setzt das Ergebnis eines Matches zurück",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Name:         "match",
+					Description:  "Die Match-ID (Vorschläge nach Teamname oder ID)",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reason",
+					Description: "Begründung für die Änderung",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "standings",
+			Description: "Zeigt die aktuelle Tabelle einer Division",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "division",
+					Description: "Die Division",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "team",
+			Description: "Zeigt Division, Roster, Bilanz, Form und nächstes Match eines Teams",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "team",
+					Description: "Die Team-Rolle",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "Der Teamname (alternativ zur Rolle, mit Vorschlägen)",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "matches",
+			Description: "Listet die Matches eines Spieltags, einer Division oder eines Teams",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "division",
+					Description: "Die Division",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "matchday",
+					Description: "Der Spieltag (leer = alle Spieltage der Division)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "team",
+					Description: "Die Team-Rolle (zeigt alle Matches des Teams)",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "Der Teamname (alternativ zur Rolle, mit Vorschlägen)",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "audit",
+			Description: "Zeigt die Änderungshistorie eines Matches oder Teams",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionInteger,
+					Name:         "match",
+					Description:  "Die Match-ID (Vorschläge nach Teamname oder ID)",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "team",
+					Description: "Die Team-Rolle",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "name",
+					Description:  "Der Teamname (alternativ zur Rolle, mit Vorschlägen)",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "backup",
+			Description: "Erstellt eine Sicherung der Datenbank und lädt sie in den Admin-Channel hoch",
+		},
+	}
+
+	for _, cmd := range commands {
+		cmd.DefaultMemberPermissions = policy.DefaultMemberPermissions(cmd.Name)
+	}
+
+	return commands
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// commandRegistry ist der Ausschnitt von *discordgo.Session, über den Slash Commands registriert werden
+type commandRegistry interface {
+	ApplicationCommands(appID, guildID string, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+	ApplicationCommandBulkOverwrite(appID, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+}
+
+var _ commandRegistry = (*discordgo.Session)(nil)
+
+// registerCommands gleicht die Slash Commands mit commandDefinitions ab.
+// Ist league.guild_id gesetzt, werden die Commands nur in dieser Guild registriert (sofort verfügbar)
+// und alte globale Commands entfernt. Fehler werden geloggt, damit ein Reconnect den Bot nicht beendet.
+func registerCommands(s *discordgo.Session) {
+	syncScopes(s, s.State.User.ID, cfg.League.GuildID, commandDefinitions())
+}
+
+// syncScopes registriert desired global bzw. in der Guild und leert bei gesetzter Guild die globalen Commands
+func syncScopes(s commandRegistry, appID, guildID string, desired []*discordgo.ApplicationCommand) {
+	if err := syncCommands(s, appID, guildID, desired); err != nil {
+		log.Printf("[Commands] Fehler beim Registrieren der Commands (%s): %v", commandScope(guildID), err)
+	}
+
+	// Früher global registrierte Commands würden sonst doppelt angezeigt
+	if guildID != "" {
+		if err := syncCommands(s, appID, "", []*discordgo.ApplicationCommand{}); err != nil {
+			log.Printf("[Commands] Fehler beim Entfernen der globalen Commands: %v", err)
+		}
+	}
+}
+
+// syncCommands überschreibt die Commands eines Scopes (guildID leer = global), sofern sie vom Sollzustand abweichen
+func syncCommands(s commandRegistry, appID, guildID string, desired []*discordgo.ApplicationCommand) error {
+	existing, err := s.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return err
+	}
+
+	added, removed, changed := diffCommands(existing, desired)
+	scope := commandScope(guildID)
+
+	if len(added)+len(removed)+len(changed) == 0 {
+		log.Printf("[Commands] %d Commands (%s) sind aktuell", len(desired), scope)
+		return nil
+	}
+
+	if _, err := s.ApplicationCommandBulkOverwrite(appID, guildID, desired); err != nil {
+		return err
+	}
+
+	log.Printf("[Commands] %s aktualisiert: neu [%s], entfernt [%s], geändert [%s]",
+		scope, strings.Join(added, ", "), strings.Join(removed, ", "), strings.Join(changed, ", "))
+	return nil
+}
+
+func commandScope(guildID string) string {
+	if guildID == "" {
+		return "global"
+	}
+	return "Guild " + guildID
+}
+
+// diffCommands vergleicht registrierte und gewünschte Commands anhand ihres Namens
+func diffCommands(existing, desired []*discordgo.ApplicationCommand) (added, removed, changed []string) {
+	current := make(map[string]*discordgo.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		current[cmd.Name] = cmd
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for _, cmd := range desired {
+		wanted[cmd.Name] = true
+		old, ok := current[cmd.Name]
+		switch {
+		case !ok:
+			added = append(added, cmd.Name)
+		case commandSignature(old) != commandSignature(cmd):
+			changed = append(changed, cmd.Name)
+		}
+	}
+
+	for name := range current {
+		if !wanted[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+
+	return added, removed, changed
+}
+
+// commandSignature fasst die Felder eines Commands zusammen, die wir selbst festlegen.
+// Von Discord vergebene Felder (ID, Version, ...) bleiben dabei außen vor.
+func commandSignature(cmd *discordgo.ApplicationCommand) string {
+	permissions := ""
+	if cmd.DefaultMemberPermissions != nil {
+		permissions = strconv.FormatInt(*cmd.DefaultMemberPermissions, 10)
+	}
+
+	data, _ := json.Marshal(struct {
+		Description string
+		Permissions string
+		Options     []*discordgo.ApplicationCommandOption
+	}{cmd.Description, permissions, cmd.Options})
+	return string(data)
+}
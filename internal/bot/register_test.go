@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeRegistry hält die registrierten Commands je Scope ("" = global)
+type fakeRegistry struct {
+	commands   map[string][]*discordgo.ApplicationCommand
+	overwrites []string
+}
+
+func (f *fakeRegistry) ApplicationCommands(appID, guildID string, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	return f.commands[guildID], nil
+}
+
+func (f *fakeRegistry) ApplicationCommandBulkOverwrite(appID, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	f.overwrites = append(f.overwrites, commandScope(guildID))
+	f.commands[guildID] = commands
+	return commands, nil
+}
+
+func slashCommand(name, description string, options ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{Name: name, Description: description, Options: options}
+}
+
+func TestDiffCommands(t *testing.T) {
+	adminOnly := int64(discordgo.PermissionAdministrator)
+	restricted := slashCommand("schedule", "Spielplan erstellen")
+	restricted.DefaultMemberPermissions = &adminOnly
+	registered := slashCommand("standings", "Tabelle anzeigen")
+	registered.ID, registered.Version = "123", "456"
+	division := &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionInteger, Name: "division", Description: "Division"}
+
+	tests := []struct {
+		name                    string
+		existing, desired       []*discordgo.ApplicationCommand
+		added, removed, changed []string
+	}{
+		{
+			name:     "unverändert",
+			existing: []*discordgo.ApplicationCommand{registered},
+			desired:  []*discordgo.ApplicationCommand{slashCommand("standings", "Tabelle anzeigen")},
+		},
+		{
+			name:    "neu",
+			desired: []*discordgo.ApplicationCommand{slashCommand("standings", "Tabelle anzeigen"), slashCommand("team", "Team anzeigen")},
+			added:   []string{"standings", "team"},
+		},
+		{
+			name:     "entfernt",
+			existing: []*discordgo.ApplicationCommand{slashCommand("team", "Team anzeigen"), slashCommand("audit", "Audit-Log"), registered},
+			desired:  []*discordgo.ApplicationCommand{slashCommand("standings", "Tabelle anzeigen")},
+			removed:  []string{"audit", "team"},
+		},
+		{
+			name:     "Beschreibung geändert",
+			existing: []*discordgo.ApplicationCommand{registered},
+			desired:  []*discordgo.ApplicationCommand{slashCommand("standings", "Aktuelle Tabelle")},
+			changed:  []string{"standings"},
+		},
+		{
+			name:     "Option hinzugefügt",
+			existing: []*discordgo.ApplicationCommand{registered},
+			desired:  []*discordgo.ApplicationCommand{slashCommand("standings", "Tabelle anzeigen", division)},
+			changed:  []string{"standings"},
+		},
+		{
+			name:     "Berechtigung geändert",
+			existing: []*discordgo.ApplicationCommand{slashCommand("schedule", "Spielplan erstellen")},
+			desired:  []*discordgo.ApplicationCommand{restricted},
+			changed:  []string{"schedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, changed := diffCommands(tt.existing, tt.desired)
+			if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(removed, tt.removed) || !reflect.DeepEqual(changed, tt.changed) {
+				t.Errorf("diffCommands = neu %v, entfernt %v, geändert %v; erwartet neu %v, entfernt %v, geändert %v",
+					added, removed, changed, tt.added, tt.removed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestSyncScopes(t *testing.T) {
+	desired := []*discordgo.ApplicationCommand{slashCommand("standings", "Tabelle anzeigen")}
+
+	tests := []struct {
+		name       string
+		guildID    string
+		existing   map[string][]*discordgo.ApplicationCommand
+		overwrites []string
+		global     int
+	}{
+		{
+			name:       "global",
+			existing:   map[string][]*discordgo.ApplicationCommand{},
+			overwrites: []string{"global"},
+			global:     1,
+		},
+		{
+			name:       "global aktuell",
+			existing:   map[string][]*discordgo.ApplicationCommand{"": {slashCommand("standings", "Tabelle anzeigen")}},
+			overwrites: nil,
+			global:     1,
+		},
+		{
+			name:       "Guild entfernt globale Commands",
+			guildID:    "guild-1",
+			existing:   map[string][]*discordgo.ApplicationCommand{"": {slashCommand("standings", "Tabelle anzeigen")}},
+			overwrites: []string{"Guild guild-1", "global"},
+			global:     0,
+		},
+		{
+			name:       "Guild ohne globale Commands",
+			guildID:    "guild-1",
+			existing:   map[string][]*discordgo.ApplicationCommand{},
+			overwrites: []string{"Guild guild-1"},
+			global:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &fakeRegistry{commands: tt.existing}
+			syncScopes(registry, "app", tt.guildID, desired)
+
+			if !reflect.DeepEqual(registry.overwrites, tt.overwrites) {
+				t.Errorf("überschrieben = %v, erwartet %v", registry.overwrites, tt.overwrites)
+			}
+			if n := len(registry.commands[""]); n != tt.global {
+				t.Errorf("%d globale Commands, erwartet %d", n, tt.global)
+			}
+			if tt.guildID != "" && len(registry.commands[tt.guildID]) != len(desired) {
+				t.Errorf("Guild-Commands = %v", registry.commands[tt.guildID])
+			}
+		})
+	}
+}
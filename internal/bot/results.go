@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/commands"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+)
+
+// resultsQueueSize begrenzt die Anzahl der noch nicht geposteten Änderungen
+const resultsQueueSize = 256
+
+// resultsTimeout begrenzt die Datenbankzugriffe für einen Eintrag der Warteschlange
+const resultsTimeout = 30 * time.Second
+
+// resultsFeed postet Ergebnisse und Spieltag-Rückblicke in die Ergebnis-Channels der Divisionen.
+// Die Einträge werden nacheinander in einer eigenen Goroutine abgearbeitet, damit Ergebnis und
+// Rückblick in der richtigen Reihenfolge erscheinen und Interaktionen nicht blockiert werden.
+type resultsFeed struct {
+	s        *discordgo.Session
+	channels map[int]string
+	queue    chan *database.AuditEntry
+}
+
+func newResultsFeed(s *discordgo.Session, channels map[int]string) *resultsFeed {
+	f := &resultsFeed{
+		s:        s,
+		channels: channels,
+		queue:    make(chan *database.AuditEntry, resultsQueueSize),
+	}
+	go f.run()
+	return f
+}
+
+// enqueue übernimmt einen Audit-Eintrag, sofern er ein Ergebnis betrifft
+func (f *resultsFeed) enqueue(entry *database.AuditEntry) {
+	if entry.EntityType != "match" {
+		return
+	}
+	switch entry.Action {
+	case "match.score", "match.disqualify", "match.bye":
+	default:
+		return
+	}
+
+	select {
+	case f.queue <- entry:
+	default:
+		log.Printf("[Results] Warteschlange voll, %s für Match %d wird nicht gepostet", entry.Action, entry.EntityID)
+	}
+}
+
+func (f *resultsFeed) run() {
+	for entry := range f.queue {
+		f.handle(entry)
+	}
+}
+
+func (f *resultsFeed) handle(entry *database.AuditEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), resultsTimeout)
+	defer cancel()
+
+	match, err := db.GetMatchByID(ctx, entry.EntityID)
+	if err != nil {
+		log.Printf("[Results] %v", err)
+		return
+	}
+
+	channelID := f.channels[match.Division]
+	if channelID == "" {
+		return
+	}
+
+	// Nur eingetragene Ergebnisse einzeln posten, Wertungen durch Disqualifikation erscheinen im Rückblick
+	if entry.Action == "match.score" {
+		if err := commands.PostResult(ctx, f.s, db, channelID, match.ID, hadScore(entry)); err != nil {
+			log.Printf("[Results] %v", err)
+		}
+	}
+
+	if err := commands.PostMatchdayRecap(ctx, f.s, db, channelID, match.Division, match.Matchday); err != nil {
+		log.Printf("[Results] %v", err)
+	}
+}
+
+// hadScore prüft, ob das Match vor der Änderung bereits ein Ergebnis hatte
+func hadScore(entry *database.AuditEntry) bool {
+	if !entry.Before.Valid {
+		return false
+	}
+	var before struct {
+		ScoreHome *int `json:"score_home"`
+		ScoreAway *int `json:"score_away"`
+	}
+	if err := json.Unmarshal([]byte(entry.Before.String), &before); err != nil {
+		return false
+	}
+	return before.ScoreHome != nil && before.ScoreAway != nil
+}
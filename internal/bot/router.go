@@ -0,0 +1,223 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// slowInteraction ist die Dauer, ab der ein Handler als langsam geloggt wird.
+// Discord erwartet innerhalb von 3 Sekunden eine erste Antwort.
+const slowInteraction = 2500 * time.Millisecond
+
+// interactionTimeout begrenzt, wie lange ein Handler auf die Datenbank warten darf
+const interactionTimeout = 30 * time.Second
+
+// handlerFunc verarbeitet eine Interaction. Ein zurückgegebener Fehler wird dem User vom Router
+// als ephemerale Meldung gesendet, sein Text ist daher die Meldung für den User.
+type handlerFunc func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error
+
+var (
+	errUnknownRoute = errors.New("Unbekannte Aktion. Bitte versuche es später erneut.")
+	errPanic        = errors.New("Interner Fehler bei der Verarbeitung. Bitte wende dich an das Staff-Team.")
+	errForbidden    = errors.New("Du hast keine Berechtigung für diesen Command.")
+)
+
+// route beschreibt, wofür ein Handler registriert wurde
+type route struct {
+	Kind       string // "command", "autocomplete", "modal" oder "button"
+	Key        string // Command-Name bzw. CustomID-Präfix
+	Permission string // Command, dessen Berechtigung geprüft wird
+}
+
+func (r route) String() string {
+	return r.Kind + " " + r.Key
+}
+
+// middleware umschließt den Handler einer Route
+type middleware func(r route, next handlerFunc) handlerFunc
+
+type prefixHandler struct {
+	prefix  string
+	handler handlerFunc
+}
+
+// router verteilt Interactions anhand von Command-Name bzw. CustomID-Präfix auf die registrierten Handler
+type router struct {
+	middleware    []middleware
+	commands      map[string]handlerFunc
+	autocompletes map[string]handlerFunc
+	modals        []prefixHandler
+	buttons       []prefixHandler
+}
+
+func newRouter(mw ...middleware) *router {
+	return &router{
+		middleware:    mw,
+		commands:      make(map[string]handlerFunc),
+		autocompletes: make(map[string]handlerFunc),
+	}
+}
+
+// command registriert einen Slash Command
+func (rt *router) command(name string, h handlerFunc) {
+	rt.commands[name] = rt.wrap(route{Kind: "command", Key: name, Permission: name}, h)
+}
+
+// autocomplete registriert die Vorschläge für einen Slash Command
+func (rt *router) autocomplete(name string, h handlerFunc) {
+	rt.autocompletes[name] = rt.wrap(route{Kind: "autocomplete", Key: name, Permission: name}, h)
+}
+
+// modal registriert ein Modal anhand des CustomID-Präfixes. permission ist der Command, zu dem das Modal gehört.
+func (rt *router) modal(prefix, permission string, h handlerFunc) {
+	rt.modals = append(rt.modals, prefixHandler{prefix, rt.wrap(route{Kind: "modal", Key: prefix, Permission: permission}, h)})
+}
+
+// button registriert einen Button anhand des CustomID-Präfixes. permission ist der Command, zu dem der Button gehört.
+func (rt *router) button(prefix, permission string, h handlerFunc) {
+	rt.buttons = append(rt.buttons, prefixHandler{prefix, rt.wrap(route{Kind: "button", Key: prefix, Permission: permission}, h)})
+}
+
+// wrap legt die Middleware um einen Handler, die erste Middleware liegt außen
+func (rt *router) wrap(r route, h handlerFunc) handlerFunc {
+	for n := len(rt.middleware) - 1; n >= 0; n-- {
+		h = rt.middleware[n](r, h)
+	}
+	return h
+}
+
+// handle ist der einzige InteractionCreate-Handler der Session
+func (rt *router) handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	rt.dispatch(s, i)
+}
+
+// dispatch führt den Handler einer Interaction aus und sendet einen zurückgegebenen Fehler an den User
+func (rt *router) dispatch(s discord.Session, i *discordgo.InteractionCreate) {
+	var h handlerFunc
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		h = rt.commands[i.ApplicationCommandData().Name]
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		h = rt.autocompletes[i.ApplicationCommandData().Name]
+	case discordgo.InteractionModalSubmit:
+		h = matchPrefix(rt.modals, i.ModalSubmitData().CustomID)
+	case discordgo.InteractionMessageComponent:
+		h = matchPrefix(rt.buttons, i.MessageComponentData().CustomID)
+	}
+
+	responder := discord.NewResponder(s)
+	if h == nil {
+		log.Printf("[Router] Kein Handler für Interaction %s (Typ %d)", interactionKey(i), i.Type)
+		responder.ReplyError(i, errUnknownRoute.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), interactionTimeout)
+	defer cancel()
+	if err := h(ctx, responder, i); err != nil {
+		responder.ReplyError(i, err.Error())
+	}
+}
+
+func matchPrefix(handlers []prefixHandler, customID string) handlerFunc {
+	for _, ph := range handlers {
+		if strings.HasPrefix(customID, ph.prefix) {
+			return ph.handler
+		}
+	}
+	return nil
+}
+
+// interactionKey gibt Command-Name bzw. CustomID einer Interaction zurück
+func interactionKey(i *discordgo.InteractionCreate) string {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		return "/" + i.ApplicationCommandData().Name
+	case discordgo.InteractionModalSubmit:
+		return i.ModalSubmitData().CustomID
+	case discordgo.InteractionMessageComponent:
+		return i.MessageComponentData().CustomID
+	}
+	return "?"
+}
+
+// interactionUser gibt den Namen des auslösenden Users zurück
+func interactionUser(i *discordgo.InteractionCreate) string {
+	switch {
+	case i.Member != nil && i.Member.User != nil:
+		return i.Member.User.Username
+	case i.User != nil:
+		return i.User.Username
+	}
+	return "unbekannt"
+}
+
+// recoverPanics fängt Panics im Handler ab, loggt den Stacktrace und gibt dem User eine Fehlermeldung
+func recoverPanics(r route, next handlerFunc) handlerFunc {
+	return func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				log.Printf("[Router] Panic in %s (%s): %v\n%s", r, interactionKey(i), p, debug.Stack())
+				err = errPanic
+			}
+		}()
+		return next(ctx, s, i)
+	}
+}
+
+// logLatency loggt Dauer, User und Fehler jeder Interaction, langsame Handler zusätzlich als Warnung
+func logLatency(r route, next handlerFunc) handlerFunc {
+	return func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		start := time.Now()
+		err := next(ctx, s, i)
+		elapsed := time.Since(start).Round(time.Millisecond)
+
+		switch {
+		case err != nil:
+			log.Printf("[Router] %s (%s) von %s nach %s: %v", r, interactionKey(i), interactionUser(i), elapsed, err)
+		case elapsed >= slowInteraction:
+			log.Printf("[Router] ⚠️ %s (%s) von %s dauerte %s", r, interactionKey(i), interactionUser(i), elapsed)
+		default:
+			log.Printf("[Router] %s (%s) von %s in %s", r, interactionKey(i), interactionUser(i), elapsed)
+		}
+		return err
+	}
+}
+
+// requirePermission lässt nur User mit Berechtigung für den zugehörigen Command durch
+func requirePermission(r route, next handlerFunc) handlerFunc {
+	return func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		if !hasPermission(i, r.Permission) {
+			return errForbidden
+		}
+		return next(ctx, s, i)
+	}
+}
+
+// autocompleteCommands gibt die Commands zurück, die mindestens eine Option mit Autocomplete haben
+func autocompleteCommands(defs []*discordgo.ApplicationCommand) []string {
+	var names []string
+	for _, cmd := range defs {
+		for _, opt := range cmd.Options {
+			if opt.Autocomplete {
+				names = append(names, cmd.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// String liefert eine lesbare Beschreibung der registrierten Routen, z.B. fürs Start-Log
+func (rt *router) String() string {
+	return fmt.Sprintf("%d Commands, %d Autocompletes, %d Modals, %d Buttons",
+		len(rt.commands), len(rt.autocompletes), len(rt.modals), len(rt.buttons))
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord/discordtest"
+	"github.com/jamie/prestigeleagueseasonfour/internal/permissions"
+)
+
+// usePolicy setzt die Berechtigungen für die Dauer eines Tests
+func usePolicy(t *testing.T, c *config.Config) {
+	t.Helper()
+
+	previous := policy
+	policy = permissions.NewPolicy(c)
+	t.Cleanup(func() { policy = previous })
+}
+
+// errorReply prüft, dass die Interaction mit einer ephemeralen Fehlermeldung beantwortet wurde
+func errorReply(t *testing.T, s *discordtest.Session, want error) {
+	t.Helper()
+
+	resp := s.LastResponse()
+	if resp == nil || resp.Data == nil {
+		t.Fatal("keine Antwort gesendet")
+	}
+	if resp.Data.Content != "❌ "+want.Error() || resp.Data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Errorf("Antwort = %q (Flags %d), erwartet ephemeral %q", resp.Data.Content, resp.Data.Flags, want.Error())
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	rt := newRouter(recoverPanics)
+	rt.command("boom", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		panic("kaputt")
+	})
+	s := discordtest.New()
+
+	rt.dispatch(s, discordtest.Command("boom"))
+
+	errorReply(t, s, errPanic)
+}
+
+func TestRecoverPanicsAfterDefer(t *testing.T) {
+	rt := newRouter(recoverPanics)
+	rt.command("boom", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+		panic("kaputt")
+	})
+	s := discordtest.New()
+
+	rt.dispatch(s, discordtest.Command("boom"))
+
+	// Die "denkt nach"-Nachricht wird durch die Fehlermeldung ersetzt
+	if edit := s.LastEdit(); edit == nil || *edit.Content != "❌ "+errPanic.Error() {
+		t.Errorf("Bearbeitung = %+v, erwartet %q", edit, errPanic)
+	}
+	if n := s.Count("InteractionRespond"); n != 1 {
+		t.Errorf("InteractionRespond %d-mal aufgerufen, erwartet 1", n)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	c := config.Default()
+	c.Roles.Admin = []string{"role-admin"}
+	usePolicy(t, c)
+
+	var calls int
+	rt := newRouter(recoverPanics, requirePermission)
+	rt.command("schedule", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		calls++
+		return nil
+	})
+	rt.command("standings", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		calls++
+		return nil
+	})
+
+	// Ohne Admin-Rolle wird der Handler nicht ausgeführt
+	s := discordtest.New()
+	rt.dispatch(s, discordtest.Command("schedule"))
+	errorReply(t, s, errForbidden)
+	if calls != 0 {
+		t.Fatalf("Handler %d-mal ausgeführt, erwartet 0", calls)
+	}
+
+	// Commands ohne Eintrag dürfen alle ausführen
+	rt.dispatch(s, discordtest.Command("standings"))
+	if calls != 1 {
+		t.Fatalf("/standings: Handler %d-mal ausgeführt, erwartet 1", calls)
+	}
+
+	admin := discordtest.Command("schedule")
+	admin.Member.Roles = []string{"role-admin"}
+	rt.dispatch(s, admin)
+	if calls != 2 {
+		t.Fatalf("Admin: Handler %d-mal ausgeführt, erwartet 2", calls)
+	}
+}
+
+func TestDispatchRepliesWithHandlerError(t *testing.T) {
+	rt := newRouter(recoverPanics)
+	rt.command("fail", func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) error {
+		return errors.New("Team nicht gefunden.")
+	})
+	s := discordtest.New()
+
+	rt.dispatch(s, discordtest.Command("fail"))
+	errorReply(t, s, errors.New("Team nicht gefunden."))
+
+	rt.dispatch(s, discordtest.Command("unknown"))
+	errorReply(t, s, errUnknownRoute)
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/commands"
+)
+
+// standingsDelay fasst schnell aufeinanderfolgende Änderungen (z.B. /schedule) zu einem Update zusammen
+const standingsDelay = 3 * time.Second
+
+// standingsTimeout begrenzt die Datenbankzugriffe für ein Update aller vorgemerkten Divisionen
+const standingsTimeout = 30 * time.Second
+
+// standingsUpdater hält die Live-Tabellen im Tabellen-Channel aktuell
+type standingsUpdater struct {
+	s         *discordgo.Session
+	channelID string
+
+	mu      sync.Mutex
+	pending map[int]bool
+	timer   *time.Timer
+}
+
+func newStandingsUpdater(s *discordgo.Session, channelID string) *standingsUpdater {
+	return &standingsUpdater{
+		s:         s,
+		channelID: channelID,
+		pending:   make(map[int]bool),
+	}
+}
+
+// schedule merkt Divisionen zum Aktualisieren vor und startet den Timer neu
+func (u *standingsUpdater) schedule(divisions ...int) {
+	if len(divisions) == 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, division := range divisions {
+		u.pending[division] = true
+	}
+
+	if u.timer != nil {
+		u.timer.Stop()
+	}
+	u.timer = time.AfterFunc(standingsDelay, u.flush)
+}
+
+// scheduleAll merkt alle Divisionen vor (z.B. nach einem Neustart)
+func (u *standingsUpdater) scheduleAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), standingsTimeout)
+	defer cancel()
+
+	divisions, err := db.GetDivisions(ctx)
+	if err != nil {
+		log.Printf("[Standings] Fehler beim Abrufen der Divisionen: %v", err)
+		return
+	}
+	u.schedule(divisions...)
+}
+
+func (u *standingsUpdater) flush() {
+	u.mu.Lock()
+	divisions := u.pending
+	u.pending = make(map[int]bool)
+	u.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), standingsTimeout)
+	defer cancel()
+
+	for division := range divisions {
+		if err := commands.UpdateStandingsMessage(ctx, u.s, db, u.channelID, division); err != nil {
+			log.Printf("[Standings] %v", err)
+		}
+	}
+}
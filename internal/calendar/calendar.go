@@ -0,0 +1,170 @@
+// Package calendar erzeugt iCalendar-Feeds (RFC 5545) aus den Matches der Liga
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+)
+
+// MatchDuration ist die angenommene Dauer eines Matches mit vereinbartem Termin
+const MatchDuration = 2 * time.Hour
+
+// uidDomain macht die UIDs der Events global eindeutig
+const uidDomain = "prestigeleague.de"
+
+// Calendar enthält die Einstellungen, mit denen Feeds erzeugt werden
+type Calendar struct {
+	// LeagueName wird dem Namen jedes Feeds vorangestellt
+	LeagueName string
+	// Location ist die Zeitzone der Spielwochen
+	Location *time.Location
+	// SeasonStart ist der Beginn der ersten Spielwoche. Die API startet nur mit
+	// Saisonstart; fehlt er trotzdem, erscheinen nur Matches mit vereinbartem Termin.
+	SeasonStart time.Time
+}
+
+// Feed beschreibt einen Kalender für ein Team oder eine Division
+type Feed struct {
+	Name    string
+	Matches []*database.Match
+	// Teams ordnet Team-IDs den Teams zu (für Namen der Gegner)
+	Teams map[int]*database.Team
+	// TeamID ist das Team, aus dessen Sicht der Feed erstellt wird (0 = Division)
+	TeamID int
+	// Stamp ist der Zeitpunkt der letzten Änderung der Daten
+	Stamp time.Time
+}
+
+// MatchdayStart gibt den Beginn der Spielwoche eines Spieltags zurück
+func (c *Calendar) MatchdayStart(matchday int) time.Time {
+	return c.SeasonStart.AddDate(0, 0, 7*(matchday-1))
+}
+
+// Render erzeugt den iCalendar-Text eines Feeds
+func (c *Calendar) Render(feed Feed) []byte {
+	var w writer
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//Prestige League//Match Schedule//DE")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.property("X-WR-CALNAME", c.feedName(feed.Name))
+	if c.Location != nil {
+		w.property("X-WR-TIMEZONE", c.Location.String())
+	}
+
+	stamp := feed.Stamp.UTC().Format("20060102T150405Z")
+	for _, match := range feed.Matches {
+		c.renderEvent(&w, feed, match, stamp)
+	}
+
+	w.line("END:VCALENDAR")
+	return []byte(w.String())
+}
+
+func (c *Calendar) renderEvent(w *writer, feed Feed, match *database.Match, stamp string) {
+	if !match.ScheduledAt.Valid && c.SeasonStart.IsZero() {
+		return
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line(fmt.Sprintf("UID:match-%d@%s", match.ID, uidDomain))
+	w.line("DTSTAMP:" + stamp)
+
+	if match.ScheduledAt.Valid {
+		start := match.ScheduledAt.Time.UTC()
+		w.line("DTSTART:" + start.Format("20060102T150405Z"))
+		w.line("DTEND:" + start.Add(MatchDuration).Format("20060102T150405Z"))
+	} else {
+		// Ohne Termin gilt das Match für die ganze Spielwoche
+		start := c.MatchdayStart(match.Matchday)
+		w.line("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+		w.line("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 7).Format("20060102"))
+		w.line("TRANSP:TRANSPARENT")
+	}
+
+	home, away := c.teamName(feed, match.TeamHomeID), ""
+	if match.TeamAwayID.Valid {
+		away = c.teamName(feed, int(match.TeamAwayID.Int64))
+	}
+
+	var summary string
+	switch {
+	case match.IsBye():
+		summary = fmt.Sprintf("Woche %d: %s – Spielfrei", match.Matchday, home)
+	case match.IsPlayed():
+		summary = fmt.Sprintf("Woche %d: %s %d:%d %s", match.Matchday, home, match.ScoreHome.Int64, match.ScoreAway.Int64, away)
+	default:
+		summary = fmt.Sprintf("Woche %d: %s vs %s", match.Matchday, home, away)
+	}
+	w.property("SUMMARY", summary)
+
+	description := []string{fmt.Sprintf("Division %d, Woche %d", match.Division, match.Matchday)}
+	if feed.TeamID != 0 && !match.IsBye() {
+		opponent := home
+		if match.TeamHomeID == feed.TeamID {
+			opponent = away
+		}
+		description = append(description, "Gegner: "+opponent)
+	}
+	if match.IsPlayed() {
+		description = append(description, fmt.Sprintf("Ergebnis: %d:%d", match.ScoreHome.Int64, match.ScoreAway.Int64))
+	} else if !match.ScheduledAt.Valid && !match.IsBye() {
+		description = append(description, "Termin noch nicht vereinbart")
+	}
+	w.property("DESCRIPTION", strings.Join(description, "\n"))
+
+	status := "CONFIRMED"
+	if !match.ScheduledAt.Valid {
+		status = "TENTATIVE"
+	}
+	w.line("STATUS:" + status)
+	w.line("END:VEVENT")
+}
+
+func (c *Calendar) feedName(name string) string {
+	if c.LeagueName == "" {
+		return name
+	}
+	return c.LeagueName + " – " + name
+}
+
+func (c *Calendar) teamName(feed Feed, teamID int) string {
+	if team, ok := feed.Teams[teamID]; ok {
+		return team.Name
+	}
+	return "TBD"
+}
+
+// writer schreibt iCalendar-Zeilen mit CRLF und faltet sie nach 75 Bytes
+type writer struct {
+	strings.Builder
+}
+
+func (w *writer) line(text string) {
+	// Folgezeilen beginnen mit einem Leerzeichen und haben ein Byte weniger Platz
+	for limit := 75; len(text) > limit; limit = 74 {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		w.WriteString(text[:cut] + "\r\n ")
+		text = text[cut:]
+	}
+	w.WriteString(text + "\r\n")
+}
+
+func (w *writer) property(name, value string) {
+	w.line(name + ":" + escape(value))
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// escape maskiert Sonderzeichen in TEXT-Werten
+func escape(text string) string {
+	return escaper.Replace(text)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
 )
 
 // isGameFree prüft ob es ein spielfreies Match ist (Team ist NULL oder ID ist 0)
@@ -14,7 +15,7 @@ func isGameFree(team *database.Team) bool {
 }
 
 // CreateMatchChannel erstellt einen Discord Channel für ein Match
-func CreateMatchChannel(s *discordgo.Session, guildID, categoryID string, match *database.Match, homeTeam, awayTeam *database.Team) (string, error) {
+func CreateMatchChannel(s discord.Session, guildID, categoryID string, match *database.Match, homeTeam, awayTeam *database.Team) (string, error) {
 	// Channel Name erstellen
 	channelName := formatChannelName(match.Division, match.Matchday, homeTeam, awayTeam)
 
@@ -103,7 +104,7 @@ func sanitizeChannelName(name string) string {
 }
 
 // sendWelcomeMessage sendet die Willkommensnachricht mit Pings
-func sendWelcomeMessage(s *discordgo.Session, channelID string, homeTeam, awayTeam *database.Team, match *database.Match) error {
+func sendWelcomeMessage(s discord.Session, channelID string, homeTeam, awayTeam *database.Team, match *database.Match) error {
 	// Rollen-Pings
 	var pings []string
 	if !isGameFree(homeTeam) && homeTeam.RoleID != "" {
@@ -239,7 +240,7 @@ func sendWelcomeMessage(s *discordgo.Session, channelID string, homeTeam, awayTe
 				},
 				{
 					Name:   "📅 Termin / Schedule",
-					Value:  "Bitte koordiniert euren Spieltermin in diesem Channel.\nPlease coordinate your match date in this channel.",
+					Value:  "Bitte koordiniert euren Spieltermin in diesem Channel und tragt ihn mit `/match_time` ein.\nPlease coordinate your match date in this channel and enter it with `/match_time`.",
 					Inline: false,
 				},
 				{
@@ -257,3 +258,58 @@ func sendWelcomeMessage(s *discordgo.Session, channelID string, homeTeam, awayTe
 	_, err := s.ChannelMessageSendEmbed(channelID, embed)
 	return err
 }
+
+// AnnounceWithdrawal entfernt ein zurückgezogenes Team aus einem Match-Channel und kündigt den Free Win an
+func AnnounceWithdrawal(s discord.Session, channelID string, withdrawnTeam, opponent *database.Team) error {
+	if !isGameFree(withdrawnTeam) && withdrawnTeam.RoleID != "" {
+		if err := s.ChannelPermissionDelete(channelID, withdrawnTeam.RoleID); err != nil {
+			return fmt.Errorf("fehler beim Entfernen der Team-Berechtigung: %w", err)
+		}
+	}
+
+	opponentName := "Unbekanntes Team"
+	if !isGameFree(opponent) {
+		opponentName = opponent.Name
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🏳️ Free Win - Team Withdrawn",
+		Description: fmt.Sprintf("**%s** hat sich aus der Liga zurückgezogen.\n**%s** has withdrawn from the league.", withdrawnTeam.Name, withdrawnTeam.Name),
+		Color:       0xFFA500,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "ℹ️ Information",
+				Value:  fmt.Sprintf("**%s** hat diese Woche spielfrei. Das Match wird nicht in der Tabelle gewertet.\n**%s** has a bye this week. The match does not count in the standings.", opponentName, opponentName),
+				Inline: false,
+			},
+		},
+	}
+
+	_, err := s.ChannelMessageSendEmbed(channelID, embed)
+	return err
+}
+
+// AddTeamToMatchChannel fügt ein nachgemeldetes Team zu einem bisherigen Freilos-Channel hinzu,
+// benennt den Channel um und sendet die Match-Informationen erneut
+func AddTeamToMatchChannel(s discord.Session, channelID string, match *database.Match, homeTeam, awayTeam, newTeam *database.Team) error {
+	if newTeam.RoleID != "" {
+		err := s.ChannelPermissionSet(channelID, newTeam.RoleID, discordgo.PermissionOverwriteTypeRole,
+			discordgo.PermissionViewChannel|discordgo.PermissionSendMessages|discordgo.PermissionReadMessageHistory, 0)
+		if err != nil {
+			return fmt.Errorf("fehler beim Setzen der Team-Berechtigung: %w", err)
+		}
+	}
+
+	_, err := s.ChannelEdit(channelID, &discordgo.ChannelEdit{
+		Name: formatChannelName(match.Division, match.Matchday, homeTeam, awayTeam),
+	})
+	if err != nil {
+		return fmt.Errorf("fehler beim Umbenennen des Channels: %w", err)
+	}
+
+	if err := sendWelcomeMessage(s, channelID, homeTeam, awayTeam, match); err != nil {
+		return fmt.Errorf("fehler beim Senden der Willkommensnachricht: %w", err)
+	}
+
+	return nil
+}
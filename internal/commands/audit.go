@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// auditLimit ist die maximale Anzahl an Einträgen, die /audit anzeigt
+const auditLimit = 15
+
+// AuditCommand zeigt die Änderungshistorie eines Matches oder Teams an
+func AuditCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	var (
+		entries []*database.AuditEntry
+		title   string
+		err     error
+	)
+
+	switch {
+	case optionMap["match"] != nil:
+		matchID := int(optionMap["match"].IntValue())
+		entries, err = db.GetAuditLogByMatch(ctx, matchID, auditLimit)
+		title = fmt.Sprintf("📜 Audit-Log Match #%d", matchID)
+	case optionMap["team"] != nil || optionMap["name"] != nil:
+		team, teamErr := teamFromOptions(ctx, db, optionMap)
+		if teamErr != nil {
+			return fmt.Errorf("Team konnte nicht gefunden werden: %w", teamErr)
+		}
+		entries, err = db.GetAuditLogByTeam(ctx, team.ID, auditLimit)
+		title = fmt.Sprintf("📜 Audit-Log %s", team.Name)
+	default:
+		return errors.New("Bitte gib ein Match oder ein Team an")
+	}
+
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen des Audit-Logs: %w", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: "Keine Einträge vorhanden.",
+		Color:       0x5865F2,
+	}
+
+	if len(entries) > 0 {
+		var lines []string
+		for _, entry := range entries {
+			lines = append(lines, formatAuditEntry(entry))
+		}
+		embed.Description = truncate(strings.Join(lines, "\n\n"), 4000)
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Neueste %d Einträge", len(entries)),
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	if err != nil {
+		log.Printf("Fehler beim Senden der Audit-Antwort: %v", err)
+	}
+	return nil
+}
+
+// MirrorAuditEntry postet einen Audit-Eintrag in den konfigurierten Log-Channel
+func MirrorAuditEntry(s discord.Session, channelID string, entry *database.AuditEntry) {
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📜 %s", entry.Action),
+		Description: truncate(formatAuditEntry(entry), 4000),
+		Color:       0x5865F2,
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed); err != nil {
+		log.Printf("[Audit] Eintrag %d konnte nicht gespiegelt werden: %v", entry.ID, err)
+	}
+}
+
+// formatAuditEntry formatiert einen Audit-Eintrag für Discord
+func formatAuditEntry(entry *database.AuditEntry) string {
+	actor := entry.ActorID
+	if actor != database.SystemActor {
+		actor = fmt.Sprintf("<@%s>", actor)
+	}
+
+	text := fmt.Sprintf("<t:%d:f> **%s** (%s #%d) von %s",
+		entry.CreatedAt.Unix(), entry.Action, entry.EntityType, entry.EntityID, actor)
+
+	if entry.Reason.Valid {
+		text += fmt.Sprintf("\nGrund: %s", entry.Reason.String)
+	}
+	if entry.Before.Valid {
+		text += fmt.Sprintf("\nVorher: `%s`", truncate(entry.Before.String, 400))
+	}
+	if entry.After.Valid {
+		text += fmt.Sprintf("\nNachher: `%s`", truncate(entry.After.String, 400))
+	}
+
+	return text
+}
+
+// truncate kürzt einen Text auf die angegebene Länge (in Runes)
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// maxUploadSize ist die Größe, die Discord Bots ohne Server-Boost hochladen dürfen
+const maxUploadSize = 10 << 20
+
+// BackupOptions legt fest, wo /backup die Sicherung ablegt und wohin sie hochgeladen wird
+type BackupOptions struct {
+	Dir       string
+	Keep      int
+	ChannelID string
+}
+
+// BackupCommand erstellt eine Sicherung der Datenbank und lädt sie in den Admin-Channel hoch
+func BackupCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.BackupRepository, opts BackupOptions) error {
+	if opts.ChannelID == "" {
+		return errors.New("Kein Admin-Channel konfiguriert (channels.admin in config.yaml)")
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	path, err := db.CreateBackup(ctx, opts.Dir, opts.Keep)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Erstellen der Sicherung: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen der Sicherung: %w", err)
+	}
+	if info.Size() > maxUploadSize {
+		return fmt.Errorf("Sicherung `%s` erstellt, ist mit %.1f MB aber zu groß zum Hochladen", path, float64(info.Size())/(1<<20))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen der Sicherung: %w", err)
+	}
+	defer file.Close()
+
+	_, err = s.ChannelMessageSendComplex(opts.ChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("💾 Datenbank-Sicherung vom %s, angefordert von <@%s>",
+			time.Now().Format("02.01.2006 15:04"), interactionUserID(i)),
+		Files: []*discordgo.File{{
+			Name:        filepath.Base(path),
+			ContentType: "application/vnd.sqlite3",
+			Reader:      file,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("Sicherung `%s` erstellt, Hochladen fehlgeschlagen: %v", path, err)
+	}
+
+	content := fmt.Sprintf("✅ Sicherung `%s` erstellt und in <#%s> hochgeladen (%.1f MB)", filepath.Base(path), opts.ChannelID, float64(info.Size())/(1<<20))
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+	})
+	return nil
+}
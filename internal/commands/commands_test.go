@@ -0,0 +1,979 @@
+package commands_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/commands"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord/discordtest"
+	"github.com/jamie/prestigeleagueseasonfour/internal/webhooks"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+// openDB erstellt eine leere In-Memory-Datenbank, die nur innerhalb des Tests existiert
+func openDB(t *testing.T) *database.Database {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	db, err := database.New(fmt.Sprintf("file:%s?mode=memory&cache=shared", name))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createTeams legt Teams mit Discord-Rolle "role-<Name>" in einer Division an
+func createTeams(t *testing.T, db *database.Database, division int, names ...string) map[string]*database.Team {
+	t.Helper()
+	ctx := context.Background()
+
+	teams := make(map[string]*database.Team, len(names))
+	for _, name := range names {
+		team, err := db.CreateTeam(ctx, name, division)
+		if err != nil {
+			t.Fatalf("CreateTeam(%s): %v", name, err)
+		}
+		if err := db.UpdateTeamRoleID(ctx, team.ID, "role-"+name); err != nil {
+			t.Fatalf("UpdateTeamRoleID(%s): %v", name, err)
+		}
+		team.RoleID = "role-" + name
+		teams[name] = team
+	}
+	return teams
+}
+
+// responseText fasst Inhalt und Embeds einer Antwort für Vergleiche zusammen
+func responseText(t *testing.T, resp *discordgo.InteractionResponse) string {
+	t.Helper()
+
+	if resp == nil || resp.Data == nil {
+		t.Fatal("keine Antwort gesendet")
+	}
+	return resp.Data.Content + embedText(resp.Data.Embeds)
+}
+
+func embedText(embeds []*discordgo.MessageEmbed) string {
+	var b strings.Builder
+	for _, embed := range embeds {
+		b.WriteString(embed.Title + "\n" + embed.Description + "\n")
+		for _, field := range embed.Fields {
+			b.WriteString(field.Name + ": " + field.Value + "\n")
+		}
+	}
+	return b.String()
+}
+
+// call führt einen Handler wie der Router aus: ein zurückgegebener Fehler wird als Antwort gesendet
+func call(ctx context.Context, handler func(context.Context, discord.Session, *discordgo.InteractionCreate, database.Store) error, s *discordtest.Session, i *discordgo.InteractionCreate, db database.Store) {
+	responder := discord.NewResponder(s)
+	if err := handler(ctx, responder, i, db); err != nil {
+		responder.ReplyError(i, err.Error())
+	}
+}
+
+// backup bindet Datenbank und Optionen, damit /backup wie die anderen Handler über call läuft
+func backup(db database.BackupRepository, opts commands.BackupOptions) func(context.Context, discord.Session, *discordgo.InteractionCreate, database.Store) error {
+	return func(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, _ database.Store) error {
+		return commands.BackupCommand(ctx, s, i, db, opts)
+	}
+}
+
+func isError(resp *discordgo.InteractionResponse) bool {
+	return resp != nil && resp.Data != nil && strings.HasPrefix(resp.Data.Content, "❌")
+}
+
+func TestScheduleCreatesRoundRobin(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	createTeams(t, db, 1, "Alpha", "Bravo", "Charlie", "Delta")
+	s := discordtest.New()
+
+	call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule", discordtest.IntOption("division", 1)), db)
+
+	resp := s.LastResponse()
+	if isError(resp) {
+		t.Fatalf("unerwarteter Fehler: %s", resp.Data.Content)
+	}
+	if text := responseText(t, resp); !strings.Contains(text, "Spielplan für Division 1 erstellt") {
+		t.Errorf("Antwort = %q", text)
+	}
+
+	matches, err := db.GetMatchesByDivision(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4 Teams: 6 Matches, jede Paarung genau einmal
+	if len(matches) != 6 {
+		t.Fatalf("%d Matches, erwartet 6", len(matches))
+	}
+	pairs := make(map[[2]int]bool)
+	for _, match := range matches {
+		a, b := match.TeamHomeID, int(match.TeamAwayID.Int64)
+		if a > b {
+			a, b = b, a
+		}
+		if pairs[[2]int{a, b}] {
+			t.Errorf("Paarung %d-%d doppelt", a, b)
+		}
+		pairs[[2]int{a, b}] = true
+	}
+}
+
+func TestScheduleDryRunChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	s := discordtest.New()
+
+	call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule",
+		discordtest.IntOption("division", 1),
+		discordtest.BoolOption("dry_run", true),
+	), db)
+
+	resp := s.LastResponse()
+	if isError(resp) {
+		t.Fatalf("unerwarteter Fehler: %s", resp.Data.Content)
+	}
+	if resp.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Error("Vorschau sollte nur für den User sichtbar sein")
+	}
+	if text := responseText(t, resp); !strings.Contains(text, "Vorschau") {
+		t.Errorf("Antwort = %q", text)
+	}
+
+	matches, err := db.GetMatchesByDivision(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Dry-Run hat %d Matches angelegt", len(matches))
+	}
+}
+
+func TestScheduleKeepsPlayedMatchdays(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	createTeams(t, db, 1, "Alpha", "Bravo", "Charlie", "Delta")
+	s := discordtest.New()
+
+	schedule := func(options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionResponse {
+		options = append([]*discordgo.ApplicationCommandInteractionDataOption{discordtest.IntOption("division", 1)}, options...)
+		call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule", options...), db)
+		return s.LastResponse()
+	}
+
+	schedule()
+	// Bei 4 Teams ist Spieltag 1 der 8er-Vorlage leer, das erste Match liegt an Spieltag 2
+	first, err := db.GetMatchesByDivision(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	played := first[0]
+	if err := db.UpdateMatchScore(ctx, played.ID, played.Version, 4, 1, "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ohne mode oder force darf ein gespieltes Match nicht überschrieben werden
+	if resp := schedule(); !isError(resp) || !strings.Contains(resp.Data.Content, "mode:remaining") {
+		t.Fatalf("Antwort = %+v, erwartet Hinweis auf mode:remaining", resp.Data)
+	}
+
+	if resp := schedule(discordtest.StringOption("mode", "remaining")); isError(resp) {
+		t.Fatalf("unerwarteter Fehler: %s", resp.Data.Content)
+	}
+
+	kept, err := db.GetMatchByID(ctx, played.ID)
+	if err != nil {
+		t.Fatalf("gespieltes Match wurde gelöscht: %v", err)
+	}
+	if !kept.ScoreHome.Valid || kept.ScoreHome.Int64 != 4 {
+		t.Errorf("Ergebnis verändert: %+v", kept)
+	}
+
+	matches, err := db.GetMatchesByDivision(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 6 {
+		t.Errorf("%d Matches, erwartet 6", len(matches))
+	}
+}
+
+func TestScheduleDryRunListsLockedMatches(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	createTeams(t, db, 1, "Alpha", "Bravo", "Charlie", "Delta")
+	s := discordtest.New()
+
+	call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule", discordtest.IntOption("division", 1)), db)
+	first, err := db.GetMatchesByDivision(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	played := first[0]
+	if err := db.UpdateMatchScore(ctx, played.ID, played.Version, 4, 1, "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule",
+		discordtest.IntOption("division", 1),
+		discordtest.BoolOption("dry_run", true),
+	), db)
+
+	resp := s.LastResponse()
+	if isError(resp) {
+		t.Fatalf("Vorschau trotz gespieltem Match abgelehnt: %s", resp.Data.Content)
+	}
+	if text := responseText(t, resp); !strings.Contains(text, "Würden gelöscht") || !strings.Contains(text, "(4:1)") {
+		t.Errorf("Vorschau listet das gespielte Match nicht: %q", text)
+	}
+	if kept, err := db.GetMatchByID(ctx, played.ID); err != nil || !kept.IsPlayed() {
+		t.Errorf("Dry-Run hat das gespielte Match verändert: %+v, %v", kept, err)
+	}
+}
+
+func TestScheduleRemainingRejectsRepeatedPairings(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie", "Delta")
+	s := discordtest.New()
+
+	// Spieltag 1 stammt nicht aus der Vorlage, Alpha gegen Bravo kommt dort später noch einmal
+	match, _ := planMatchday1(t, db, teams)
+	if err := db.UpdateMatchScore(ctx, match.ID, match.Version, 4, 1, "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := func(options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionResponse {
+		options = append([]*discordgo.ApplicationCommandInteractionDataOption{
+			discordtest.IntOption("division", 1),
+			discordtest.StringOption("mode", "remaining"),
+		}, options...)
+		call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule", options...), db)
+		return s.LastResponse()
+	}
+
+	if resp := schedule(); !isError(resp) || !strings.Contains(resp.Data.Content, "bereits in Woche 1") {
+		t.Fatalf("Antwort = %+v, erwartet Ablehnung wegen Alpha gegen Bravo", resp.Data)
+	}
+	if matches, _ := db.GetMatchesByDivision(ctx, 1); len(matches) != 2 {
+		t.Fatalf("%d Matches nach Ablehnung, erwartet 2", len(matches))
+	}
+
+	if resp := schedule(discordtest.BoolOption("force", true)); isError(resp) {
+		t.Fatalf("unerwarteter Fehler mit force: %s", resp.Data.Content)
+	}
+}
+
+func TestScheduleRequiresThreeTeams(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	createTeams(t, db, 2, "Alpha", "Bravo")
+	s := discordtest.New()
+
+	call(ctx, commands.ScheduleCommand, s, discordtest.Command("schedule", discordtest.IntOption("division", 2)), db)
+
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "zu wenige Teams") {
+		t.Errorf("Antwort = %+v", resp.Data)
+	}
+}
+
+// planMatchday1 legt Spieltag 1 mit einem Match und einem Freilos an
+func planMatchday1(t *testing.T, db *database.Database, teams map[string]*database.Team) (match, bye *database.Match) {
+	t.Helper()
+	ctx := context.Background()
+
+	away := teams["Bravo"].ID
+	err := db.ReplaceMatches(ctx, 1, 1, []database.MatchPlan{
+		{Matchday: 1, TeamHomeID: teams["Alpha"].ID, TeamAwayID: &away},
+		{Matchday: 1, TeamHomeID: teams["Charlie"].ID},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceMatches: %v", err)
+	}
+
+	matches, err := db.GetMatchesByDivisionAndMatchday(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range matches {
+		if m.IsBye() {
+			bye = m
+		} else {
+			match = m
+		}
+	}
+	return match, bye
+}
+
+func createChannels() *discordgo.InteractionCreate {
+	return discordtest.Command("createchannels",
+		discordtest.IntOption("division", 1),
+		discordtest.IntOption("matchday", 1),
+		discordtest.StringOption("category", "category-1"),
+	)
+}
+
+func TestCreateChannels(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	match, bye := planMatchday1(t, db, teams)
+	s := discordtest.New()
+
+	call(ctx, commands.CreateChannelsCommand, s, createChannels(), db)
+
+	if resp := s.LastResponse(); resp.Type != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+		t.Errorf("Antworttyp = %v, erwartet Deferred", resp.Type)
+	}
+	edit := s.LastEdit()
+	if edit == nil || edit.Embeds == nil {
+		t.Fatal("Ergebnis wurde nicht nachgereicht")
+	}
+	if text := embedText(*edit.Embeds); !strings.Contains(text, "2 Channels") || !strings.Contains(text, "0 Fehler") {
+		t.Errorf("Ergebnis = %q", text)
+	}
+
+	if len(s.Channels) != 2 {
+		t.Fatalf("%d Channels erstellt, erwartet 2", len(s.Channels))
+	}
+
+	match, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := s.Channels[match.ChannelID.String]
+	if channel == nil {
+		t.Fatalf("Channel-ID %q des Matches wurde nicht erstellt", match.ChannelID.String)
+	}
+	if channel.Name != "div1-woche1-alpha-bravo" || channel.ParentID != "category-1" {
+		t.Errorf("Channel = %q in %q", channel.Name, channel.ParentID)
+	}
+
+	allowed := make(map[string]bool)
+	for _, overwrite := range channel.PermissionOverwrites {
+		if overwrite.Allow&discordgo.PermissionViewChannel != 0 {
+			allowed[overwrite.ID] = true
+		}
+		if overwrite.ID == discordtest.GuildID && overwrite.Deny&discordgo.PermissionViewChannel == 0 {
+			t.Error("@everyone darf den Match-Channel sehen")
+		}
+	}
+	if !allowed["role-Alpha"] || !allowed["role-Bravo"] || allowed["role-Charlie"] {
+		t.Errorf("Rollen mit Zugriff = %v", allowed)
+	}
+
+	messages := s.MessagesIn(channel.ID)
+	if len(messages) != 2 || messages[0].Content != "<@&role-Alpha> <@&role-Bravo>" {
+		t.Fatalf("Nachrichten im Match-Channel = %+v", messages)
+	}
+	if !strings.Contains(embedText(messages[1].Embeds), "Match Information") {
+		t.Errorf("Willkommensnachricht = %q", embedText(messages[1].Embeds))
+	}
+
+	bye, err = db.GetMatchByID(ctx, bye.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byeMessages := s.MessagesIn(bye.ChannelID.String)
+	if len(byeMessages) != 2 || !strings.Contains(embedText(byeMessages[1].Embeds), "Spielfreie Woche") {
+		t.Errorf("Nachrichten im Freilos-Channel = %+v", byeMessages)
+	}
+
+	// Ein zweiter Aufruf überspringt die vorhandenen Channels
+	call(ctx, commands.CreateChannelsCommand, s, createChannels(), db)
+	if len(s.Channels) != 2 {
+		t.Errorf("%d Channels nach zweitem Aufruf, erwartet 2", len(s.Channels))
+	}
+	if text := embedText(*s.LastEdit().Embeds); !strings.Contains(text, "2 bereits vorhanden") {
+		t.Errorf("Ergebnis = %q", text)
+	}
+}
+
+func TestCreateChannelsReportsFailures(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	match, _ := planMatchday1(t, db, teams)
+	s := discordtest.New()
+	s.FailOn("GuildChannelCreateComplex", errors.New("missing permissions"))
+
+	call(ctx, commands.CreateChannelsCommand, s, createChannels(), db)
+
+	text := embedText(*s.LastEdit().Embeds)
+	if !strings.Contains(text, "0 Channels") || !strings.Contains(text, "2 Fehler") || !strings.Contains(text, "missing permissions") {
+		t.Errorf("Ergebnis = %q", text)
+	}
+
+	match, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match.ChannelID.Valid && match.ChannelID.String != "" {
+		t.Errorf("Channel-ID %q gespeichert, obwohl kein Channel erstellt wurde", match.ChannelID.String)
+	}
+}
+
+func TestCreateChannelsDeletesChannelWhenWelcomeFails(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	planMatchday1(t, db, teams)
+	s := discordtest.New()
+	s.FailOn("ChannelMessageSendEmbed", errors.New("rate limited"))
+
+	call(ctx, commands.CreateChannelsCommand, s, createChannels(), db)
+
+	if len(s.Channels) != 0 {
+		t.Errorf("%d Channels übrig, erwartet 0", len(s.Channels))
+	}
+	if len(s.Deleted) != 2 {
+		t.Errorf("%d Channels gelöscht, erwartet 2", len(s.Deleted))
+	}
+}
+
+// matchChannel legt Spieltag 1 an und verknüpft das Match mit einem bestehenden Channel
+func matchChannel(t *testing.T, db *database.Database, s *discordtest.Session) *database.Match {
+	t.Helper()
+	ctx := context.Background()
+
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	match, _ := planMatchday1(t, db, teams)
+	s.AddChannel("match-channel", "div1-woche1-alpha-bravo")
+	if err := db.UpdateMatchChannelID(ctx, match.ID, "match-channel"); err != nil {
+		t.Fatal(err)
+	}
+	return match
+}
+
+func TestReportResult(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	s := discordtest.New()
+	match := matchChannel(t, db, s)
+
+	call(ctx, commands.ReportResultCommand, s, discordtest.InChannel(discordtest.Command("report_result"), "match-channel"), db)
+
+	resp := s.LastResponse()
+	if resp.Type != discordgo.InteractionResponseModal {
+		t.Fatalf("Antworttyp = %v, erwartet Modal", resp.Type)
+	}
+	customID := resp.Data.CustomID
+	if customID != commands.ReportResultModalID(match) {
+		t.Fatalf("Modal-ID = %q", customID)
+	}
+
+	call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(customID, "4", "2"), "match-channel"), db)
+
+	if resp := s.LastResponse(); isError(resp) {
+		t.Fatalf("unerwarteter Fehler: %s", resp.Data.Content)
+	}
+
+	reported, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reported.ScoreHome.Int64 != 4 || reported.ScoreAway.Int64 != 2 || reported.ReportedBy.String != discordtest.UserID {
+		t.Errorf("Match = %d:%d von %q", reported.ScoreHome.Int64, reported.ScoreAway.Int64, reported.ReportedBy.String)
+	}
+
+	messages := s.MessagesIn("match-channel")
+	if len(messages) != 1 || !strings.Contains(embedText(messages[0].Embeds), "**Alpha**") {
+		t.Errorf("Ergebnis im Match-Channel = %+v", messages)
+	}
+}
+
+func TestReportResultQueuesEvents(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	db.SetWebhookRouter(webhooks.NewPublisher(db, config.WebhooksConfig{
+		Endpoints: []config.WebhookEndpoint{{URL: "https://hooks.example", Secret: "secret"}},
+	}))
+	s := discordtest.New()
+	match := matchChannel(t, db, s)
+	submit := discordtest.InChannel(discordtest.ModalSubmit(commands.ReportResultModalID(match), "4", "2"), "match-channel")
+
+	call(ctx, commands.HandleReportResultModal, s, submit, db)
+	if resp := s.LastResponse(); isError(resp) {
+		t.Fatalf("unerwarteter Fehler: %s", resp.Data.Content)
+	}
+
+	deliveries, err := db.GetWebhookDeliveries(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var eventTypes []string
+	for _, delivery := range deliveries {
+		eventTypes = append(eventTypes, delivery.EventType)
+	}
+	if got := strings.Join(eventTypes, ","); got != leagueapi.EventMatchConfirmed+","+leagueapi.EventMatchReported {
+		t.Fatalf("Events = %s, erwartet match.reported und match.confirmed", got)
+	}
+
+	// Die abgelehnte zweite Meldung ändert nichts und verschickt daher auch nichts
+	call(ctx, commands.HandleReportResultModal, s, submit, db)
+	if deliveries, err := db.GetWebhookDeliveries(ctx, 10); err != nil || len(deliveries) != 2 {
+		t.Errorf("%d Zustellungen nach abgelehnter Meldung, erwartet 2 (%v)", len(deliveries), err)
+	}
+}
+
+func TestReportResultRejectsInvalidScores(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	s := discordtest.New()
+	match := matchChannel(t, db, s)
+	customID := commands.ReportResultModalID(match)
+
+	for _, scores := range [][2]string{{"4", "4"}, {"2", "1"}, {"5", "0"}, {"x", "4"}} {
+		call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(customID, scores[0], scores[1]), "match-channel"), db)
+		if resp := s.LastResponse(); !isError(resp) {
+			t.Errorf("%s:%s wurde akzeptiert", scores[0], scores[1])
+		}
+	}
+
+	unchanged, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.IsPlayed() {
+		t.Errorf("ungültiges Ergebnis gespeichert: %d:%d", unchanged.ScoreHome.Int64, unchanged.ScoreAway.Int64)
+	}
+	if len(s.MessagesIn("match-channel")) != 0 {
+		t.Error("ungültiges Ergebnis wurde im Channel angekündigt")
+	}
+}
+
+// TestReportResultRejectsStaleSubmission öffnet das Modal für zwei Spieler gleichzeitig.
+// Nur die erste Meldung wird gespeichert, die zweite bekommt das eingetragene Ergebnis angezeigt.
+func TestReportResultRejectsStaleSubmission(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	s := discordtest.New()
+	match := matchChannel(t, db, s)
+	customID := commands.ReportResultModalID(match)
+
+	call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(customID, "4", "2"), "match-channel"), db)
+	if resp := s.LastResponse(); isError(resp) {
+		t.Fatalf("erste Meldung abgelehnt: %s", resp.Data.Content)
+	}
+
+	second := discordtest.InChannel(discordtest.ModalSubmit(customID, "1", "4"), "match-channel")
+	second.Member.User = &discordgo.User{ID: "user-2"}
+	call(ctx, commands.HandleReportResultModal, s, second, db)
+
+	resp := s.LastResponse()
+	if !isError(resp) || !strings.Contains(resp.Data.Content, "bereits ein Ergebnis") {
+		t.Fatalf("zweite Meldung = %+v", resp.Data)
+	}
+	if text := embedText(resp.Data.Embeds); !strings.Contains(text, "**4 : 2**") || !strings.Contains(text, "<@"+discordtest.UserID+">") {
+		t.Errorf("angezeigtes Ergebnis = %q", text)
+	}
+
+	reported, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reported.ScoreHome.Int64 != 4 || reported.ScoreAway.Int64 != 2 || reported.ReportedBy.String != discordtest.UserID {
+		t.Errorf("Match = %d:%d von %q", reported.ScoreHome.Int64, reported.ScoreAway.Int64, reported.ReportedBy.String)
+	}
+	if len(s.MessagesIn("match-channel")) != 1 {
+		t.Error("abgelehnte Meldung wurde im Channel angekündigt")
+	}
+
+	// Mit dem aktuellen Stand ist eine Korrektur möglich
+	call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(commands.ReportResultModalID(reported), "1", "4"), "match-channel"), db)
+	if resp := s.LastResponse(); isError(resp) {
+		t.Fatalf("Korrektur abgelehnt: %s", resp.Data.Content)
+	}
+}
+
+// memoryStore hält Teams und Matches im Speicher, damit Handler ohne Datenbank getestet werden können.
+// Methoden, die ein Test nicht braucht, fallen auf den eingebetteten nil-Store und brechen den Test ab.
+type memoryStore struct {
+	database.Store
+	teams   map[int]*database.Team
+	matches map[int]*database.Match
+}
+
+func (m *memoryStore) WithActor(string) database.Store      { return m }
+func (m *memoryStore) WithReason(string) database.Store     { return m }
+func (m *memoryStore) WithEvent(string, any) database.Store { return m }
+
+func (m *memoryStore) GetTeamByID(_ context.Context, id int) (*database.Team, error) {
+	if team, ok := m.teams[id]; ok {
+		return team, nil
+	}
+	return nil, fmt.Errorf("team %d nicht gefunden", id)
+}
+
+func (m *memoryStore) GetMatchByID(_ context.Context, id int) (*database.Match, error) {
+	if match, ok := m.matches[id]; ok {
+		copied := *match
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("match %d nicht gefunden", id)
+}
+
+func (m *memoryStore) UpdateMatchScore(_ context.Context, id, version, scoreHome, scoreAway int, reportedBy string) error {
+	match, ok := m.matches[id]
+	if !ok {
+		return fmt.Errorf("match %d nicht gefunden", id)
+	}
+	if match.Version != version {
+		current := *match
+		return &database.ResultConflictError{Current: &current}
+	}
+	match.ScoreHome = sql.NullInt64{Int64: int64(scoreHome), Valid: true}
+	match.ScoreAway = sql.NullInt64{Int64: int64(scoreAway), Valid: true}
+	match.ReportedBy = sql.NullString{String: reportedBy, Valid: true}
+	match.Version++
+	return nil
+}
+
+// TestReportResultMemoryStore meldet Ergebnisse gegen einen Store ohne Datenbank
+func TestReportResultMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{
+		teams: map[int]*database.Team{
+			1: {ID: 1, Name: "Alpha", Division: 1},
+			2: {ID: 2, Name: "Bravo", Division: 1},
+		},
+		matches: map[int]*database.Match{
+			7: {ID: 7, Division: 1, Matchday: 1, TeamHomeID: 1, TeamAwayID: sql.NullInt64{Int64: 2, Valid: true}, Version: 3},
+		},
+	}
+	s := discordtest.New()
+	s.AddChannel("match-channel", "div1-woche1-alpha-bravo")
+
+	stale := &database.Match{ID: 7, Version: 2}
+	call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(commands.ReportResultModalID(stale), "4", "1"), "match-channel"), store)
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "zurückgesetzt") {
+		t.Fatalf("veraltete Meldung = %+v", resp.Data)
+	}
+
+	call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(commands.ReportResultModalID(store.matches[7]), "4", "1"), "match-channel"), store)
+	if resp := s.LastResponse(); isError(resp) {
+		t.Fatalf("Meldung abgelehnt: %s", resp.Data.Content)
+	}
+	if match := store.matches[7]; match.ScoreHome.Int64 != 4 || match.ScoreAway.Int64 != 1 || match.Version != 4 {
+		t.Errorf("Match = %+v", match)
+	}
+	if len(s.MessagesIn("match-channel")) != 1 {
+		t.Error("Ergebnis wurde nicht im Channel angekündigt")
+	}
+}
+
+// TestReportResultAfterWithdraw meldet ein Ergebnis über ein Modal, das vor /withdraw geöffnet wurde
+func TestReportResultAfterWithdraw(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	s := discordtest.New()
+	match := matchChannel(t, db, s)
+	customID := commands.ReportResultModalID(match)
+
+	if _, _, err := db.WithdrawTeam(ctx, int(match.TeamAwayID.Int64)); err != nil {
+		t.Fatal(err)
+	}
+	bye, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil || !bye.IsBye() || bye.Version == match.Version {
+		t.Fatalf("Match nach Rückzug = %+v, %v", bye, err)
+	}
+
+	for _, id := range []string{customID, commands.ReportResultModalID(bye)} {
+		call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(id, "4", "2"), "match-channel"), db)
+		if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "Freilos") {
+			t.Fatalf("Meldung für Freilos (%s) = %+v", id, resp.Data)
+		}
+	}
+
+	after, err := db.GetMatchByID(ctx, match.ID)
+	if err != nil || after.IsPlayed() {
+		t.Fatalf("Freilos nach Meldung = %+v, %v", after, err)
+	}
+
+	call(ctx, commands.ReportResultCommand, s, discordtest.InChannel(discordtest.Command("report_result"), "match-channel"), db)
+	if resp := s.LastResponse(); !isError(resp) {
+		t.Errorf("Modal für Freilos geöffnet: %+v", resp.Data)
+	}
+}
+
+// TestSetResultRejectsBye setzt als Admin ein Ergebnis für ein Freilos
+func TestSetResultRejectsBye(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	_, bye := planMatchday1(t, db, teams)
+	s := discordtest.New()
+
+	call(ctx, commands.SetResultCommand, s, discordtest.Command("set_result",
+		discordtest.IntOption("match", bye.ID),
+		discordtest.IntOption("home", 4),
+		discordtest.IntOption("away", 0),
+		discordtest.StringOption("reason", "Test"),
+	), db)
+
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "Freilos") {
+		t.Fatalf("Antwort = %+v", resp.Data)
+	}
+	if got, err := db.GetMatchByID(ctx, bye.ID); err != nil || got.IsPlayed() {
+		t.Fatalf("Freilos nach /set_result = %+v, %v", got, err)
+	}
+}
+
+func TestReportResultOutsideMatchChannel(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	s := discordtest.New()
+	matchChannel(t, db, s)
+
+	call(ctx, commands.ReportResultCommand, s, discordtest.InChannel(discordtest.Command("report_result"), "general"), db)
+
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "Match-Channel") {
+		t.Errorf("Antwort = %+v", resp.Data)
+	}
+}
+
+func TestDisqualify(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	match, _ := planMatchday1(t, db, teams)
+	s := discordtest.New()
+
+	disqualify := discordtest.Command("disqualify", discordtest.RoleOption("team", "role-Bravo"))
+	call(ctx, commands.DisqualifyCommand, s, disqualify, db)
+
+	if resp := s.LastResponse(); isError(resp) || !strings.Contains(responseText(t, resp), "**Bravo** wurde disqualifiziert") {
+		t.Fatalf("Antwort = %+v", resp.Data)
+	}
+
+	team, err := db.GetTeamByID(ctx, teams["Bravo"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !team.IsDisqualified {
+		t.Error("Team ist nicht disqualifiziert")
+	}
+
+	// Bravo spielt auswärts, verliert also 3:0
+	match, err = db.GetMatchByID(ctx, match.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match.ScoreHome.Int64 != 3 || match.ScoreAway.Int64 != 0 || match.ReportedBy.String != database.DisqualifiedReporter {
+		t.Errorf("Match = %d:%d von %q", match.ScoreHome.Int64, match.ScoreAway.Int64, match.ReportedBy.String)
+	}
+
+	entries, err := db.GetAuditLogByTeam(ctx, team.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries[0].ActorID != discordtest.UserID {
+		t.Errorf("Audit-Log = %+v, erwartet Eintrag von %s", entries, discordtest.UserID)
+	}
+
+	call(ctx, commands.DisqualifyCommand, s, disqualify, db)
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "bereits disqualifiziert") {
+		t.Errorf("Antwort = %+v", resp.Data)
+	}
+}
+
+func TestDisqualifyByName(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	teams := createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	s := discordtest.New()
+
+	call(ctx, commands.DisqualifyCommand, s, discordtest.Command("disqualify", discordtest.StringOption("name", "charlie")), db)
+
+	if resp := s.LastResponse(); isError(resp) {
+		t.Fatalf("unerwarteter Fehler: %s", resp.Data.Content)
+	}
+	team, err := db.GetTeamByID(ctx, teams["Charlie"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !team.IsDisqualified {
+		t.Error("Team ist nicht disqualifiziert")
+	}
+}
+
+func TestDisqualifyUnknownTeam(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	createTeams(t, db, 1, "Alpha", "Bravo", "Charlie")
+	s := discordtest.New()
+
+	call(ctx, commands.DisqualifyCommand, s, discordtest.Command("disqualify", discordtest.RoleOption("team", "role-unknown")), db)
+
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "nicht gefunden") {
+		t.Errorf("Antwort = %+v", resp.Data)
+	}
+}
+
+// TestReportResultConcurrent meldet alle Matches eines Spieltags gleichzeitig, während parallel
+// gelesen wird und ein zweiter Prozess (z.B. die API) über eine eigene Verbindung schreibt.
+// Keine Meldung darf an einer Sperre scheitern oder verloren gehen.
+func TestReportResultConcurrent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "league.db")
+	db, err := database.New(path)
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	defer db.Close()
+	other, err := database.New(path)
+	if err != nil {
+		t.Fatalf("database.New (zweiter Prozess): %v", err)
+	}
+	defer other.Close()
+
+	names := make([]string, 16)
+	for n := range names {
+		names[n] = fmt.Sprintf("Team %02d", n+1)
+	}
+	teams := createTeams(t, db, 1, names...)
+
+	plans := make([]database.MatchPlan, 0, len(names)/2)
+	for n := 0; n < len(names); n += 2 {
+		away := teams[names[n+1]].ID
+		plans = append(plans, database.MatchPlan{Matchday: 1, TeamHomeID: teams[names[n]].ID, TeamAwayID: &away})
+	}
+	if err := db.ReplaceMatches(ctx, 1, 1, plans); err != nil {
+		t.Fatalf("ReplaceMatches: %v", err)
+	}
+	matches, err := db.GetMatchesByDivisionAndMatchday(ctx, 1, 1)
+	if err != nil || len(matches) != len(plans) {
+		t.Fatalf("GetMatchesByDivisionAndMatchday = %d Matches, %v", len(matches), err)
+	}
+
+	s := discordtest.New()
+	for _, match := range matches {
+		channelID := fmt.Sprintf("match-%d", match.ID)
+		s.AddChannel(channelID, channelID)
+		if err := db.UpdateMatchChannelID(ctx, match.ID, channelID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const rounds = 5
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+
+	for _, match := range matches {
+		match := match
+		wg.Add(3)
+
+		// Meldung über das Modal
+		go func() {
+			defer wg.Done()
+			customID := commands.ReportResultModalID(match)
+			call(ctx, commands.HandleReportResultModal, s, discordtest.InChannel(discordtest.ModalSubmit(customID, "4", "1"), fmt.Sprintf("match-%d", match.ID)), db)
+		}()
+
+		// Leser wie Tabelle und Autocomplete
+		go func() {
+			defer wg.Done()
+			for n := 0; n < rounds; n++ {
+				if _, err := db.GetMatchesByDivision(ctx, 1); err != nil {
+					fail(fmt.Errorf("GetMatchesByDivision: %w", err))
+				}
+			}
+		}()
+
+		// Schreiber über die zweite Verbindung
+		go func() {
+			defer wg.Done()
+			for n := 0; n < rounds; n++ {
+				at := time.Date(2026, 3, 2, 19, n, 0, 0, time.UTC)
+				if err := other.SetMatchTime(ctx, match.ID, &at); err != nil {
+					fail(fmt.Errorf("SetMatchTime(%d): %w", match.ID, err))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		t.Error(err)
+	}
+	for _, resp := range s.Responses {
+		if isError(resp) {
+			t.Errorf("Meldung abgelehnt: %s", resp.Data.Content)
+		}
+	}
+	if len(s.Responses) != len(matches) {
+		t.Errorf("%d Antworten, erwartet %d", len(s.Responses), len(matches))
+	}
+
+	for _, match := range matches {
+		reported, err := db.GetMatchByID(ctx, match.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reported.ScoreHome.Int64 != 4 || reported.ScoreAway.Int64 != 1 || !reported.ScheduledAt.Valid {
+			t.Errorf("Match %d = %d:%d, Termin %v", match.ID, reported.ScoreHome.Int64, reported.ScoreAway.Int64, reported.ScheduledAt)
+		}
+		entries, err := db.GetAuditLogByMatch(ctx, match.ID, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1+1+rounds+1 {
+			t.Errorf("Match %d hat %d Audit-Einträge, erwartet %d", match.ID, len(entries), 1+1+rounds+1)
+		}
+	}
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.New(filepath.Join(t.TempDir(), "league.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	defer db.Close()
+	createTeams(t, db, 1, "Alpha", "Bravo")
+	s := discordtest.New()
+	s.AddChannel("admin", "liga-leitung")
+	opts := commands.BackupOptions{Dir: filepath.Join(t.TempDir(), "backups"), Keep: 3, ChannelID: "admin"}
+
+	call(ctx, backup(db, opts), s, discordtest.Command("backup"), nil)
+
+	if edit := s.LastEdit(); edit == nil || !strings.HasPrefix(*edit.Content, "✅") {
+		t.Fatalf("Antwort = %+v", edit)
+	}
+	backups, err := database.ListBackups(opts.Dir)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("ListBackups = %v, %v", backups, err)
+	}
+	messages := s.MessagesIn("admin")
+	if len(messages) != 1 || len(messages[0].Files) != 1 || messages[0].Files[0].Name != filepath.Base(backups[0]) {
+		t.Fatalf("Nachrichten im Admin-Channel = %+v", messages)
+	}
+
+	// Ohne Admin-Channel wird keine Sicherung erstellt
+	opts.ChannelID = ""
+	call(ctx, backup(db, opts), s, discordtest.Command("backup"), nil)
+	if resp := s.LastResponse(); !isError(resp) || !strings.Contains(resp.Data.Content, "channels.admin") {
+		t.Errorf("Antwort ohne Admin-Channel = %+v", resp.Data)
+	}
+}
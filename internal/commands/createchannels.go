@@ -1,36 +1,37 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/channels"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
 )
 
 // CreateChannelsCommand erstellt Discord Channels für alle Matches einer Division und eines Matchdays
-func CreateChannelsCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *database.Database) {
+func CreateChannelsCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
 	options := i.ApplicationCommandData().Options
 	if len(options) < 3 {
-		respondError(s, i, "Bitte gib Division, Matchday und Kategorie-ID an")
-		return
+		return errors.New("Bitte gib Division, Matchday und Kategorie-ID an")
 	}
 
 	division := int(options[0].IntValue())
 	matchday := int(options[1].IntValue())
 	categoryID := options[2].StringValue()
+	db = db.WithActor(interactionUserID(i))
 
 	// Matches der Division und des Matchdays abrufen
-	matches, err := db.GetMatchesByDivisionAndMatchday(division, matchday)
+	matches, err := db.GetMatchesByDivisionAndMatchday(ctx, division, matchday)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Abrufen der Matches: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Abrufen der Matches: %w", err)
 	}
 
 	if len(matches) == 0 {
-		respondError(s, i, "Keine Matches für diese Division und Spieltag gefunden")
-		return
+		return errors.New("Keine Matches für diese Division und Spieltag gefunden")
 	}
 
 	// Defer Antwort für längere Operationen
@@ -54,7 +55,7 @@ func CreateChannelsCommand(s *discordgo.Session, i *discordgo.InteractionCreate,
 		// Teams abrufen
 		var homeTeam *database.Team
 		if match.TeamHomeID != 0 {
-			homeTeam, err = db.GetTeamByID(match.TeamHomeID)
+			homeTeam, err = db.GetTeamByID(ctx, match.TeamHomeID)
 			if err != nil {
 				errors++
 				errorMsg := fmt.Sprintf("Match ID %d: Fehler beim Abrufen des Heimteams (ID %d): %v", match.ID, match.TeamHomeID, err)
@@ -66,7 +67,7 @@ func CreateChannelsCommand(s *discordgo.Session, i *discordgo.InteractionCreate,
 
 		var awayTeam *database.Team
 		if match.TeamAwayID.Valid && match.TeamAwayID.Int64 != 0 {
-			awayTeam, err = db.GetTeamByID(int(match.TeamAwayID.Int64))
+			awayTeam, err = db.GetTeamByID(ctx, int(match.TeamAwayID.Int64))
 			if err != nil {
 				errors++
 				errorMsg := fmt.Sprintf("Match ID %d: Fehler beim Abrufen des Auswärtsteams (ID %d): %v", match.ID, match.TeamAwayID.Int64, err)
@@ -101,7 +102,7 @@ func CreateChannelsCommand(s *discordgo.Session, i *discordgo.InteractionCreate,
 		}
 
 		// Channel-ID in Datenbank speichern
-		if err := db.UpdateMatchChannelID(match.ID, channelID); err != nil {
+		if err := db.UpdateMatchChannelID(ctx, match.ID, channelID); err != nil {
 			// Channel wieder löschen bei DB-Fehler
 			if _, delErr := s.ChannelDelete(channelID); delErr != nil {
 				log.Printf("[CreateChannels] Match ID %d: Channel %s konnte nicht gelöscht werden: %v", match.ID, channelID, delErr)
@@ -186,4 +187,5 @@ func CreateChannelsCommand(s *discordgo.Session, i *discordgo.InteractionCreate,
 	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Embeds: &[]*discordgo.MessageEmbed{embed},
 	})
+	return nil
 }
@@ -1,41 +1,49 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
 )
 
 // DisqualifyCommand disqualifiziert ein Team über seine Discord-Rolle
-func DisqualifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *database.Database) {
+func DisqualifyCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
 		optionMap[opt.Name] = opt
 	}
 
-	roleID := optionMap["team"].RoleValue(nil, "").ID
+	db = db.WithActor(interactionUserID(i))
 
-	// Team anhand der Rolle finden
-	team, err := db.GetTeamByRoleID(roleID)
+	// Team anhand der Rolle oder des Namens finden
+	team, err := teamFromOptions(ctx, db, optionMap)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Team mit dieser Rolle nicht gefunden: %v", err))
-		return
+		return fmt.Errorf("Team konnte nicht gefunden werden: %w", err)
 	}
 
 	// Prüfen, ob Team bereits disqualified ist
 	if team.IsDisqualified {
-		respondError(s, i, fmt.Sprintf("Team **%s** ist bereits disqualifiziert", team.Name))
-		return
+		return fmt.Errorf("Team **%s** ist bereits disqualifiziert", team.Name)
 	}
 
 	// Team disqualifizieren
-	err = db.DisqualifyTeam(team.ID)
+	err = db.WithEvent(leagueapi.EventTeamDisqualified, leagueapi.TeamDisqualifiedEvent{
+		Team: leagueapi.Team{
+			ID:             team.ID,
+			Name:           team.Name,
+			Division:       team.Division,
+			IsDisqualified: true,
+			IsWithdrawn:    team.IsWithdrawn,
+		},
+	}).DisqualifyTeam(ctx, team.ID)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Disqualifizieren des Teams: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Disqualifizieren des Teams: %w", err)
 	}
 
 	// Erfolgs-Embed erstellen
@@ -62,36 +70,40 @@ func DisqualifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db
 	if err != nil {
 		log.Printf("Fehler beim Senden der Disqualify-Antwort: %v", err)
 	}
+	return nil
 }
 
-// RequalifyCommand hebt die Disqualifikation eines Teams auf
-func RequalifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *database.Database) {
+// RequalifyCommand hebt die Disqualifikation eines Teams auf und stellt auf Wunsch die Matches wieder her
+func RequalifyCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
 		optionMap[opt.Name] = opt
 	}
 
-	roleID := optionMap["team"].RoleValue(nil, "").ID
+	restoreMatches := optionMap["restore_matches"] != nil && optionMap["restore_matches"].BoolValue()
+	db = db.WithActor(interactionUserID(i))
 
-	// Team anhand der Rolle finden
-	team, err := db.GetTeamByRoleID(roleID)
+	// Team anhand der Rolle oder des Namens finden
+	team, err := teamFromOptions(ctx, db, optionMap)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Team mit dieser Rolle nicht gefunden: %v", err))
-		return
+		return fmt.Errorf("Team konnte nicht gefunden werden: %w", err)
 	}
 
-	// Prüfen, ob Team disqualified ist
-	if !team.IsDisqualified {
-		respondError(s, i, fmt.Sprintf("Team **%s** ist nicht disqualifiziert", team.Name))
-		return
+	disqualifiedMatches, err := db.GetDisqualifiedMatches(ctx, team.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der gewerteten Matches: %w", err)
+	}
+
+	// Prüfen, ob Team disqualified ist (bereits requalifizierte Teams können noch Matches wiederherstellen)
+	if !team.IsDisqualified && (!restoreMatches || len(disqualifiedMatches) == 0) {
+		return fmt.Errorf("Team **%s** ist nicht disqualifiziert", team.Name)
 	}
 
 	// Disqualifikation aufheben
-	err = db.RequalifyTeam(team.ID)
+	restored, transferred, err := db.RequalifyTeam(ctx, team.ID, restoreMatches)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Requalifizieren des Teams: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Requalifizieren des Teams: %w", err)
 	}
 
 	// Erfolgs-Embed erstellen
@@ -99,13 +111,35 @@ func RequalifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *
 		Title:       "✅ Disqualifikation aufgehoben",
 		Description: fmt.Sprintf("**%s** ist nicht mehr disqualifiziert.", team.Name),
 		Color:       0x00FF00, // Grün
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Hinweis",
-				Value:  "Bereits gewertete Matches (0:3) werden NICHT automatisch zurückgesetzt.",
-				Inline: false,
-			},
-		},
+	}
+	if !team.IsDisqualified {
+		embed.Title = "✅ Matches wiederhergestellt"
+		embed.Description = fmt.Sprintf("Die Matches von **%s** wurden überprüft.", team.Name)
+	}
+
+	if restoreMatches {
+		value := fmt.Sprintf("%d von %d Matches wurden auf ihren vorherigen Stand zurückgesetzt.", len(restored), len(disqualifiedMatches))
+		if len(transferred) > 0 {
+			value += fmt.Sprintf("\n%d Matches bleiben gewertet, weil der Gegner ebenfalls disqualifiziert ist.", len(transferred))
+		}
+		if skipped := len(disqualifiedMatches) - len(restored) - len(transferred); skipped > 0 {
+			value += fmt.Sprintf("\n%d Matches wurden seit der Disqualifikation geändert und bleiben unverändert.", skipped)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Matches wiederhergestellt",
+			Value:  value,
+			Inline: false,
+		})
+	} else {
+		value := "Bereits gewertete Matches (0:3) werden NICHT automatisch zurückgesetzt."
+		if len(disqualifiedMatches) > 0 {
+			value += fmt.Sprintf("\n%d Matches können mit `/requalify restore_matches:True` wiederhergestellt werden.", len(disqualifiedMatches))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Hinweis",
+			Value:  value,
+			Inline: false,
+		})
 	}
 
 	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -118,4 +152,5 @@ func RequalifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *
 	if err != nil {
 		log.Printf("Fehler beim Senden der Requalify-Antwort: %v", err)
 	}
+	return nil
 }
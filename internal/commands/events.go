@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+// Liga-Events werden nicht direkt verschickt, sondern über database.Store.WithEvent zusammen
+// mit der Änderung gespeichert, die sie beschreiben. Schlägt die Änderung fehl, gibt es auch kein Event.
+
+// matchResultEvent beschreibt das Ergebnis, das für ein Match eingetragen wird
+func matchResultEvent(ctx context.Context, db database.TeamRepository, match *database.Match, scoreHome, scoreAway int, reportedBy, reason string) leagueapi.MatchResultEvent {
+	data := leagueapi.MatchResultEvent{
+		MatchID:    match.ID,
+		Division:   match.Division,
+		Matchday:   match.Matchday,
+		HomeTeam:   eventTeam(ctx, db, match.TeamHomeID),
+		ScoreHome:  scoreHome,
+		ScoreAway:  scoreAway,
+		ReportedBy: reportedBy,
+		Reason:     reason,
+	}
+	if match.TeamAwayID.Valid {
+		away := eventTeam(ctx, db, int(match.TeamAwayID.Int64))
+		data.AwayTeam = &away
+	}
+	return data
+}
+
+func eventTeam(ctx context.Context, db database.TeamRepository, teamID int) leagueapi.EventTeam {
+	team := leagueapi.EventTeam{ID: teamID}
+	if t, err := db.GetTeamByID(ctx, teamID); err == nil {
+		team.Name = t.Name
+	}
+	return team
+}
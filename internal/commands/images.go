@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/render"
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+)
+
+var renderer *render.Renderer
+
+// SetRenderer legt fest, womit Tabellen und Ergebnisse als Grafik gezeichnet werden (nil = nur Embeds)
+func SetRenderer(r *render.Renderer) {
+	renderer = r
+}
+
+// standingsImage zeichnet die Tabelle einer Division als Anhang (nil ohne Renderer oder bei Fehlern)
+func standingsImage(division int, rows []*standings.Row) *discordgo.File {
+	if renderer == nil {
+		return nil
+	}
+	png, err := renderer.Standings(division, rows, time.Now())
+	if err != nil {
+		log.Printf("[Render] Tabelle Division %d: %v", division, err)
+		return nil
+	}
+	return pngFile(fmt.Sprintf("tabelle-division-%d.png", division), png)
+}
+
+// resultsImage zeichnet die Ergebnisse eines Spieltags als Anhang (nil ohne Renderer oder bei Fehlern)
+func resultsImage(division, matchday int, matches []*database.Match, teamNames map[int]string) *discordgo.File {
+	if renderer == nil {
+		return nil
+	}
+	png, err := renderer.MatchdayResults(division, matchday, matches, teamNames)
+	if err != nil {
+		log.Printf("[Render] Ergebnisse Division %d, Woche %d: %v", division, matchday, err)
+		return nil
+	}
+	return pngFile(fmt.Sprintf("ergebnisse-division-%d-woche-%d.png", division, matchday), png)
+}
+
+func pngFile(name string, png []byte) *discordgo.File {
+	return &discordgo.File{
+		Name:        name,
+		ContentType: "image/png",
+		Reader:      bytes.NewReader(png),
+	}
+}
+
+// attachImage zeigt einen Anhang als Bild im Embed an
+func attachImage(embed *discordgo.MessageEmbed, file *discordgo.File) {
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + file.Name}
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+const (
+	// matchesPerPage ist die Anzahl der Matches pro Seite von /matches
+	matchesPerPage = 8
+
+	// MatchesButtonPrefix ist der Präfix der CustomID der Blätter-Buttons von /matches
+	MatchesButtonPrefix = "matches:"
+)
+
+// matchQuery beschreibt, welche Matches /matches anzeigt.
+// Sie steckt vollständig in der CustomID der Buttons, damit das Blättern auch nach einem Neustart funktioniert.
+type matchQuery struct {
+	Division int
+	Matchday int // 0 = alle Spieltage
+	TeamID   int // 0 = alle Teams der Division
+}
+
+// customID kodiert die Abfrage und die Zielseite für einen Button
+func (q matchQuery) customID(page int) string {
+	return fmt.Sprintf("%s%d:%d:%d:%d", MatchesButtonPrefix, q.Division, q.Matchday, q.TeamID, page)
+}
+
+// parseMatchesCustomID liest Abfrage und Seite aus der CustomID eines Buttons
+func parseMatchesCustomID(customID string) (matchQuery, int, error) {
+	var q matchQuery
+	var page int
+	_, err := fmt.Sscanf(strings.TrimPrefix(customID, MatchesButtonPrefix), "%d:%d:%d:%d", &q.Division, &q.Matchday, &q.TeamID, &page)
+	if err != nil {
+		return q, 0, fmt.Errorf("ungültige button-id %q: %w", customID, err)
+	}
+	return q, page, nil
+}
+
+// MatchesCommand listet die Matches eines Spieltags, einer Division oder eines Teams auf
+func MatchesCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	var q matchQuery
+	switch {
+	case optionMap["team"] != nil || optionMap["name"] != nil:
+		team, err := teamFromOptions(ctx, db, optionMap)
+		if err != nil {
+			return fmt.Errorf("Team konnte nicht gefunden werden: %w", err)
+		}
+		q = matchQuery{Division: team.Division, TeamID: team.ID}
+	case optionMap["division"] != nil:
+		q.Division = int(optionMap["division"].IntValue())
+		if opt, ok := optionMap["matchday"]; ok {
+			q.Matchday = int(opt.IntValue())
+		}
+	default:
+		return errors.New("Bitte gib eine Division (optional mit Spieltag) oder ein Team an")
+	}
+
+	embed, components, err := matchesPage(ctx, db, q, 1)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Matches: %w", err)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	return nil
+}
+
+// HandleMatchesPage blättert in einer /matches Liste und ersetzt die Nachricht durch die gewählte Seite
+func HandleMatchesPage(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	q, page, err := parseMatchesCustomID(i.MessageComponentData().CustomID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Blättern: %w", err)
+	}
+
+	embed, components, err := matchesPage(ctx, db, q, page)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Matches: %w", err)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	return nil
+}
+
+// matchesPage baut eine Seite der Match-Liste mit den Buttons zum Blättern
+func matchesPage(ctx context.Context, db database.Repository, q matchQuery, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	var (
+		matches []*database.Match
+		title   string
+		err     error
+	)
+
+	names, err := teamNames(ctx, db, q.Division)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case q.TeamID != 0:
+		matches, err = db.GetMatchesByTeam(ctx, q.TeamID)
+		title = fmt.Sprintf("📅 Matches von %s", names[q.TeamID])
+	case q.Matchday != 0:
+		matches, err = db.GetMatchesByDivisionAndMatchday(ctx, q.Division, q.Matchday)
+		title = fmt.Sprintf("📅 Division %d – Woche %d", q.Division, q.Matchday)
+	default:
+		matches, err = db.GetMatchesByDivision(ctx, q.Division)
+		title = fmt.Sprintf("📅 Matches Division %d", q.Division)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(matches) == 0 {
+		return nil, nil, errors.New("keine matches gefunden")
+	}
+
+	pages := (len(matches) + matchesPerPage - 1) / matchesPerPage
+	if page < 1 {
+		page = 1
+	}
+	if page > pages {
+		page = pages
+	}
+
+	start := (page - 1) * matchesPerPage
+	end := start + matchesPerPage
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	lines := make([]string, 0, end-start)
+	for _, match := range matches[start:end] {
+		lines = append(lines, matchLine(match, names, q.Matchday == 0))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: strings.Join(lines, "\n\n"),
+		Color:       0x5865F2,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Seite %d/%d • %d Matches", page, pages, len(matches)),
+		},
+	}
+
+	if pages == 1 {
+		return embed, []discordgo.MessageComponent{}, nil
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "◀ Zurück",
+					Style:    discordgo.SecondaryButton,
+					CustomID: q.customID(page - 1),
+					Disabled: page == 1,
+				},
+				discordgo.Button{
+					Label:    "Weiter ▶",
+					Style:    discordgo.SecondaryButton,
+					CustomID: q.customID(page + 1),
+					Disabled: page == pages,
+				},
+			},
+		},
+	}
+
+	return embed, components, nil
+}
+
+// matchLine beschreibt ein Match mit Ergebnis, Termin und Link zum Match-Channel
+func matchLine(match *database.Match, names map[int]string, withMatchday bool) string {
+	prefix := fmt.Sprintf("`#%d`", match.ID)
+	if withMatchday {
+		prefix += fmt.Sprintf(" Woche %d •", match.Matchday)
+	}
+
+	if match.IsBye() {
+		return fmt.Sprintf("%s %s – spielfrei / bye", prefix, names[match.TeamHomeID])
+	}
+
+	home, away := names[match.TeamHomeID], names[int(match.TeamAwayID.Int64)]
+	line := fmt.Sprintf("%s %s vs %s", prefix, home, away)
+	if match.IsPlayed() {
+		line = fmt.Sprintf("%s %s **%d:%d** %s", prefix, home, match.ScoreHome.Int64, match.ScoreAway.Int64, away)
+	}
+
+	var details []string
+	if match.ScheduledAt.Valid && !match.IsPlayed() {
+		details = append(details, fmt.Sprintf("🗓️ <t:%d:f>", match.ScheduledAt.Time.Unix()))
+	}
+	if match.ChannelID.Valid && match.ChannelID.String != "" {
+		details = append(details, fmt.Sprintf("💬 <#%s>", match.ChannelID.String))
+	}
+	if len(details) > 0 {
+		line += "\n" + strings.Join(details, " • ")
+	}
+
+	return line
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// matchTimeLayout ist das Eingabeformat für Spieltermine
+const matchTimeLayout = "02.01.2006 15:04"
+
+// MatchTimeCommand trägt den vereinbarten Spieltermin eines Matches ein (nur in Match-Channels).
+// Ohne Zeitangabe wird der Termin entfernt.
+func MatchTimeCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store, loc *time.Location) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	db = db.WithActor(interactionUserID(i))
+
+	// Match anhand Channel-ID abrufen
+	match, err := db.GetMatchByChannelID(ctx, i.ChannelID)
+	if err != nil {
+		return errors.New("Dieser Command kann nur in einem Match-Channel verwendet werden")
+	}
+
+	if match.IsBye() {
+		return errors.New("Für ein Freilos kann kein Spieltermin eingetragen werden")
+	}
+
+	if match.IsPlayed() {
+		return errors.New("Für dieses Match wurde bereits ein Ergebnis eingetragen")
+	}
+
+	var scheduledAt *time.Time
+	if opt, ok := optionMap["time"]; ok {
+		parsed, err := time.ParseInLocation(matchTimeLayout, strings.TrimSpace(opt.StringValue()), loc)
+		if err != nil {
+			return errors.New("Ungültiges Format. Bitte `TT.MM.JJJJ HH:MM` verwenden, z.B. `14.10.2026 20:30`")
+		}
+		scheduledAt = &parsed
+	}
+
+	if err := db.SetMatchTime(ctx, match.ID, scheduledAt); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Spieltermins: %w", err)
+	}
+
+	homeTeam, awayTeam, err := matchTeamNames(ctx, db, match)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Teams: %w", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "📅 Spieltermin entfernt / Match time removed",
+		Description: fmt.Sprintf("**%s** vs **%s** (Woche %d)", homeTeam, awayTeam, match.Matchday),
+		Color:       0xffaa00,
+	}
+
+	if scheduledAt != nil {
+		embed.Title = "📅 Spieltermin vereinbart / Match time set"
+		embed.Color = 0x00ff00
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   "Termin / Time",
+				Value:  fmt.Sprintf("<t:%d:F> (<t:%d:R>)", scheduledAt.Unix(), scheduledAt.Unix()),
+				Inline: false,
+			},
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+	return nil
+}
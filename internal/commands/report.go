@@ -1,36 +1,52 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
 )
 
+// ReportResultModalPrefix ist der Präfix der CustomID des Ergebnis-Modals.
+// Die CustomID lautet report_result:<Match-ID>:<Version>, siehe ReportResultModalID.
+const ReportResultModalPrefix = "report_result:"
+
+// ReportResultModalID gibt die CustomID des Ergebnis-Modals für den aktuellen Stand eines Matches zurück.
+// Die Version sorgt dafür, dass eine Meldung abgelehnt wird, wenn inzwischen jemand anderes gemeldet hat.
+func ReportResultModalID(match *database.Match) string {
+	return fmt.Sprintf("%s%d:%d", ReportResultModalPrefix, match.ID, match.Version)
+}
+
 // ReportResultCommand öffnet ein Modal zum Eintragen des Ergebnisses
-func ReportResultCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *database.Database) {
+func ReportResultCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
 	// Match anhand Channel-ID abrufen
-	match, err := db.GetMatchByChannelID(i.ChannelID)
+	match, err := db.GetMatchByChannelID(ctx, i.ChannelID)
 	if err != nil {
-		respondError(s, i, "Dieser Command kann nur in einem Match-Channel verwendet werden")
-		return
+		return errors.New("Dieser Command kann nur in einem Match-Channel verwendet werden")
+	}
+
+	if match.IsBye() {
+		return errors.New("Für ein Freilos kann kein Ergebnis eingetragen werden")
 	}
 
 	// Teams abrufen
-	homeTeam, err := db.GetTeamByID(match.TeamHomeID)
+	homeTeam, err := db.GetTeamByID(ctx, match.TeamHomeID)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Abrufen des Home Teams: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Abrufen des Home Teams: %w", err)
 	}
 
 	awayTeamName := "Free Win"
 	if match.TeamAwayID.Valid {
-		awayTeam, err := db.GetTeamByID(int(match.TeamAwayID.Int64))
+		awayTeam, err := db.GetTeamByID(ctx, int(match.TeamAwayID.Int64))
 		if err != nil {
-			respondError(s, i, fmt.Sprintf("Fehler beim Abrufen des Away Teams: %v", err))
-			return
+			return fmt.Errorf("Fehler beim Abrufen des Away Teams: %w", err)
 		}
 		awayTeamName = awayTeam.Name
 	}
@@ -45,7 +61,7 @@ func ReportResultCommand(s *discordgo.Session, i *discordgo.InteractionCreate, d
 	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseModal,
 		Data: &discordgo.InteractionResponseData{
-			CustomID: fmt.Sprintf("report_result:%d", match.ID),
+			CustomID: ReportResultModalID(match),
 			Title:    "Match Ergebnis eintragen",
 			Components: []discordgo.MessageComponent{
 				discordgo.ActionsRow{
@@ -79,85 +95,89 @@ func ReportResultCommand(s *discordgo.Session, i *discordgo.InteractionCreate, d
 	})
 
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Öffnen des Modals: %v", err))
+		return fmt.Errorf("Fehler beim Öffnen des Modals: %w", err)
 	}
+	return nil
 }
 
 // HandleReportResultModal verarbeitet das Modal Submit
-func HandleReportResultModal(s *discordgo.Session, i *discordgo.InteractionCreate, db *database.Database) {
+func HandleReportResultModal(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
 	data := i.ModalSubmitData()
 
-	// Match-ID aus CustomID extrahieren
-	var matchID int
-	_, err := fmt.Sscanf(data.CustomID, "report_result:%d", &matchID)
+	// Match-ID und gelesene Version aus CustomID extrahieren
+	idPart, versionPart, hasVersion := strings.Cut(strings.TrimPrefix(data.CustomID, ReportResultModalPrefix), ":")
+	matchID, err := strconv.Atoi(idPart)
 	if err != nil {
-		respondError(s, i, "Ungültige Modal-ID")
-		return
+		return errors.New("Ungültige Modal-ID")
 	}
 
 	// Match abrufen für Division-Check
-	match, err := db.GetMatchByID(matchID)
+	match, err := db.GetMatchByID(ctx, matchID)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Match nicht gefunden: %v", err))
-		return
+		return fmt.Errorf("Match nicht gefunden: %w", err)
 	}
 
-	// Best-of Format basierend auf Division
-	maxScore := 3 // Best of 5
-	if match.Division == 1 || match.Division == 2 {
-		maxScore = 4 // Best of 7
+	// Das Match kann seit dem Öffnen des Modals z.B. durch /withdraw zum Freilos geworden sein
+	if match.IsBye() {
+		return errors.New("Für ein Freilos kann kein Ergebnis eingetragen werden")
+	}
+
+	// Modals, die vor dem Update ohne Version geöffnet wurden, gelten für den aktuellen Stand
+	version := match.Version
+	if hasVersion {
+		if version, err = strconv.Atoi(versionPart); err != nil {
+			return errors.New("Ungültige Modal-ID")
+		}
 	}
 
+	maxScore := maxScoreForDivision(match.Division)
+
 	// Scores aus Modal auslesen
 	scoreHomeStr := data.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
 	scoreAwayStr := data.Components[1].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
 
 	scoreHome, err := strconv.Atoi(scoreHomeStr)
 	if err != nil || scoreHome < 0 || scoreHome > maxScore {
-		respondError(s, i, fmt.Sprintf("Ungültiger Score für Home Team (muss 0-%d sein)", maxScore))
-		return
+		return fmt.Errorf("Ungültiger Score für Home Team (muss 0-%d sein)", maxScore)
 	}
 
 	scoreAway, err := strconv.Atoi(scoreAwayStr)
 	if err != nil || scoreAway < 0 || scoreAway > maxScore {
-		respondError(s, i, fmt.Sprintf("Ungültiger Score für Away Team (muss 0-%d sein)", maxScore))
-		return
-	}
-
-	// Best-of validieren (einer muss maxScore haben)
-	if scoreHome != maxScore && scoreAway != maxScore {
-		respondError(s, i, fmt.Sprintf("Ein Team muss %d Wins haben", maxScore))
-		return
+		return fmt.Errorf("Ungültiger Score für Away Team (muss 0-%d sein)", maxScore)
 	}
 
-	if scoreHome == maxScore && scoreAway == maxScore {
-		respondError(s, i, fmt.Sprintf("Beide Teams können nicht %d Wins haben", maxScore))
-		return
+	if err := validateResult(match.Division, scoreHome, scoreAway); err != nil {
+		return err
 	}
 
 	// Teams abrufen
-	homeTeam, err := db.GetTeamByID(match.TeamHomeID)
+	homeTeam, err := db.GetTeamByID(ctx, match.TeamHomeID)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Abrufen des Teams: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Abrufen des Teams: %w", err)
 	}
 
 	awayTeamName := "Free Win"
 	if match.TeamAwayID.Valid {
-		awayTeam, err := db.GetTeamByID(int(match.TeamAwayID.Int64))
+		awayTeam, err := db.GetTeamByID(ctx, int(match.TeamAwayID.Int64))
 		if err != nil {
-			respondError(s, i, fmt.Sprintf("Fehler beim Abrufen des Teams: %v", err))
-			return
+			return fmt.Errorf("Fehler beim Abrufen des Teams: %w", err)
 		}
 		awayTeamName = awayTeam.Name
 	}
 
-	// Ergebnis in Datenbank speichern
+	// Ergebnis in Datenbank speichern. Eine Meldung des Teams ist sofort gültig,
+	// daher gehen match.reported und match.confirmed mit der Änderung raus.
 	reportedBy := i.Member.User.ID
-	err = db.UpdateMatchScore(matchID, scoreHome, scoreAway, reportedBy)
+	event := matchResultEvent(ctx, db, match, scoreHome, scoreAway, reportedBy, "")
+	err = db.WithEvent(leagueapi.EventMatchReported, event).
+		WithEvent(leagueapi.EventMatchConfirmed, event).
+		UpdateMatchScore(ctx, matchID, version, scoreHome, scoreAway, reportedBy)
+	var conflict *database.ResultConflictError
+	if errors.As(err, &conflict) {
+		return respondResultConflict(ctx, s, i, db, conflict.Current)
+	}
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Speichern des Ergebnisses: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Speichern des Ergebnisses: %w", err)
 	}
 
 	// Bestätigung an User
@@ -210,4 +230,103 @@ func HandleReportResultModal(s *discordgo.Session, i *discordgo.InteractionCreat
 	}
 
 	s.ChannelMessageSendEmbed(i.ChannelID, embed)
+	return nil
+}
+
+// respondResultConflict lehnt eine Meldung ab, weil das Ergebnis seit dem Öffnen des Modals
+// eingetragen oder geändert wurde, und zeigt den aktuellen Stand
+func respondResultConflict(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.TeamRepository, current *database.Match) error {
+	content := "❌ Für dieses Match wurde bereits ein Ergebnis eingetragen. / A result has already been reported for this match."
+	if !current.IsPlayed() {
+		content = "❌ Das Ergebnis wurde inzwischen zurückgesetzt, bitte `/report_result` erneut ausführen. / The result was cleared in the meantime, please run `/report_result` again."
+	}
+
+	homeName, awayName, err := matchTeamNames(ctx, db, current)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Teams: %w", err)
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Aktuelles Ergebnis / Current result",
+			Value:  formatScore(current),
+			Inline: true,
+		},
+	}
+	if current.IsPlayed() && current.ReportedBy.String != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "👤 Eingetragen von / Reported by",
+			Value:  reporterMention(current.ReportedBy.String),
+			Inline: true,
+		})
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "📊 Match Ergebnis / Match Result",
+				Description: fmt.Sprintf("**%s** vs **%s**", homeName, awayName),
+				Color:       0xFFA500,
+				Fields:      fields,
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// reporterMention erwähnt den Melder eines Ergebnisses, sofern es ein Discord-User ist
+func reporterMention(reportedBy string) string {
+	if reportedBy == database.DisqualifiedReporter {
+		return "Disqualifikation"
+	}
+	return fmt.Sprintf("<@%s>", reportedBy)
+}
+
+// maxScoreForDivision gibt die nötigen Siege für das Best-of Format der Division zurück
+func maxScoreForDivision(division int) int {
+	if division == 1 || division == 2 {
+		return 4 // Best of 7
+	}
+	return 3 // Best of 5
+}
+
+// validateResult prüft, ob ein Ergebnis zum Best-of Format der Division passt
+func validateResult(division, scoreHome, scoreAway int) error {
+	maxScore := maxScoreForDivision(division)
+
+	if scoreHome < 0 || scoreHome > maxScore || scoreAway < 0 || scoreAway > maxScore {
+		return fmt.Errorf("Scores müssen zwischen 0 und %d liegen", maxScore)
+	}
+
+	// Best-of validieren (einer muss maxScore haben)
+	if scoreHome != maxScore && scoreAway != maxScore {
+		return fmt.Errorf("Ein Team muss %d Wins haben", maxScore)
+	}
+
+	if scoreHome == maxScore && scoreAway == maxScore {
+		return fmt.Errorf("Beide Teams können nicht %d Wins haben", maxScore)
+	}
+
+	return nil
+}
+
+// matchTeamNames gibt die Namen von Heim- und Auswärtsteam eines Matches zurück
+func matchTeamNames(ctx context.Context, db database.TeamRepository, match *database.Match) (string, string, error) {
+	homeTeam, err := db.GetTeamByID(ctx, match.TeamHomeID)
+	if err != nil {
+		return "", "", err
+	}
+
+	awayTeamName := "Free Win"
+	if match.TeamAwayID.Valid {
+		awayTeam, err := db.GetTeamByID(ctx, int(match.TeamAwayID.Int64))
+		if err != nil {
+			return "", "", err
+		}
+		awayTeamName = awayTeam.Name
+	}
+
+	return homeTeam.Name, awayTeamName, nil
 }
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+// SetResultCommand setzt das Ergebnis eines Matches als Admin (funktioniert aus jedem Channel)
+func SetResultCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	matchID := int(optionMap["match"].IntValue())
+	scoreHome := int(optionMap["home"].IntValue())
+	scoreAway := int(optionMap["away"].IntValue())
+	reason := optionMap["reason"].StringValue()
+	adminID := interactionUserID(i)
+	db = db.WithActor(adminID).WithReason(reason)
+
+	before, err := db.GetMatchByID(ctx, matchID)
+	if err != nil {
+		return fmt.Errorf("Match nicht gefunden: %w", err)
+	}
+
+	if err := validateResult(before.Division, scoreHome, scoreAway); err != nil {
+		return err
+	}
+
+	event := matchResultEvent(ctx, db, before, scoreHome, scoreAway, adminID, reason)
+	err = db.WithEvent(leagueapi.EventMatchConfirmed, event).
+		UpdateMatchScore(ctx, matchID, before.Version, scoreHome, scoreAway, adminID)
+	var conflict *database.ResultConflictError
+	if errors.As(err, &conflict) {
+		return fmt.Errorf("Das Ergebnis von Match #%d wurde gerade geändert (jetzt %s), bitte prüfen und erneut setzen", matchID, formatScore(conflict.Current))
+	}
+	if errors.Is(err, database.ErrByeResult) {
+		return fmt.Errorf("Match #%d ist ein Freilos, dafür kann kein Ergebnis eingetragen werden", matchID)
+	}
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Ergebnisses: %w", err)
+	}
+
+	return respondResultChange(ctx, s, i, db, before, "🛠️ Ergebnis gesetzt / Result set", reason)
+}
+
+// ClearResultCommand setzt das Ergebnis eines Matches als Admin zurück
+func ClearResultCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	matchID := int(optionMap["match"].IntValue())
+	reason := ""
+	if opt, ok := optionMap["reason"]; ok {
+		reason = opt.StringValue()
+	}
+	db = db.WithActor(interactionUserID(i)).WithReason(reason)
+
+	before, err := db.GetMatchByID(ctx, matchID)
+	if err != nil {
+		return fmt.Errorf("Match nicht gefunden: %w", err)
+	}
+
+	if !before.ScoreHome.Valid && !before.ScoreAway.Valid {
+		return fmt.Errorf("Match #%d hat noch kein Ergebnis", matchID)
+	}
+
+	if err := db.ClearMatchScore(ctx, matchID); err != nil {
+		return fmt.Errorf("Fehler beim Zurücksetzen des Ergebnisses: %w", err)
+	}
+
+	return respondResultChange(ctx, s, i, db, before, "🧹 Ergebnis zurückgesetzt / Result cleared", reason)
+}
+
+// respondResultChange beantwortet den Command und postet die Änderung in den Match-Channel
+func respondResultChange(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store, before *database.Match, title, reason string) error {
+	after, err := db.GetMatchByID(ctx, before.ID)
+	if err != nil {
+		return fmt.Errorf("Match nicht gefunden: %w", err)
+	}
+
+	homeName, awayName, err := matchTeamNames(ctx, db, after)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Teams: %w", err)
+	}
+
+	if reason == "" {
+		reason = "-"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: fmt.Sprintf("**%s** vs **%s**\nDivision %d - Woche %d (Match #%d)", homeName, awayName, after.Division, after.Matchday, after.ID),
+		Color:       0xFFA500,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Vorher / Before",
+				Value:  formatScore(before),
+				Inline: true,
+			},
+			{
+				Name:   "Nachher / After",
+				Value:  formatScore(after),
+				Inline: true,
+			},
+			{
+				Name:   "📝 Grund / Reason",
+				Value:  reason,
+				Inline: false,
+			},
+			{
+				Name:   "👤 Geändert von / Changed by",
+				Value:  fmt.Sprintf("<@%s>", interactionUserID(i)),
+				Inline: true,
+			},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+	if err != nil {
+		log.Printf("Fehler beim Senden der Ergebnis-Antwort: %v", err)
+	}
+
+	// Änderung im Match-Channel bekannt geben, sofern der Command nicht dort ausgeführt wurde
+	if after.ChannelID.Valid && after.ChannelID.String != "" && after.ChannelID.String != i.ChannelID {
+		if _, err := s.ChannelMessageSendEmbed(after.ChannelID.String, embed); err != nil {
+			log.Printf("[Result] Match ID %d: Nachricht im Match-Channel fehlgeschlagen: %v", after.ID, err)
+		}
+	}
+	return nil
+}
+
+// formatScore formatiert den Spielstand eines Matches
+func formatScore(match *database.Match) string {
+	if !match.ScoreHome.Valid || !match.ScoreAway.Valid {
+		return "Kein Ergebnis / No result"
+	}
+	return fmt.Sprintf("**%d : %d**", match.ScoreHome.Int64, match.ScoreAway.Int64)
+}
@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+)
+
+const (
+	// upsetGap ist der Mindestabstand in der Tabelle, ab dem ein Sieg als Überraschung gilt
+	upsetGap = 3
+	// recapTopTeams ist die Anzahl der Teams, die im Rückblick aus der Tabelle gezeigt werden
+	recapTopTeams = 3
+)
+
+// PostResult postet das Ergebnis eines Matches in den Ergebnis-Channel der Division.
+// Mit corrected wird das Ergebnis als Korrektur eines bereits geposteten Ergebnisses markiert.
+func PostResult(ctx context.Context, s discord.Session, db database.Store, channelID string, matchID int, corrected bool) error {
+	match, err := db.GetMatchByID(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	if match.IsBye() || !match.IsPlayed() {
+		return nil
+	}
+
+	names, err := teamNames(ctx, db, match.Division)
+	if err != nil {
+		return err
+	}
+
+	home := names[match.TeamHomeID]
+	away := names[int(match.TeamAwayID.Int64)]
+	scoreHome, scoreAway := match.ScoreHome.Int64, match.ScoreAway.Int64
+
+	winner := home
+	if scoreAway > scoreHome {
+		winner = away
+	}
+
+	title := fmt.Sprintf("✅ Ergebnis Division %d – Woche %d / Result", match.Division, match.Matchday)
+	color := 0x57F287
+	if corrected {
+		title = fmt.Sprintf("✏️ Korrigiertes Ergebnis Division %d – Woche %d / Corrected result", match.Division, match.Matchday)
+		color = 0xFEE75C
+	}
+
+	description := fmt.Sprintf("**%s** %d : %d **%s**\n🏆 %s", home, scoreHome, scoreAway, away, winner)
+	if match.ReportedBy.String == database.DisqualifiedReporter {
+		description += "\n*Gewertet wegen Disqualifikation / Awarded due to disqualification*"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: description,
+		Color:       color,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Match #%d", match.ID),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed); err != nil {
+		return fmt.Errorf("fehler beim Posten des Ergebnisses von Match %d: %w", match.ID, err)
+	}
+	return nil
+}
+
+// PostMatchdayRecap postet den Rückblick eines Spieltags, sobald alle Matches ein Ergebnis haben.
+// Pro Division und Spieltag wird höchstens ein Rückblick gepostet.
+func PostMatchdayRecap(ctx context.Context, s discord.Session, db database.Store, channelID string, division, matchday int) error {
+	matches, err := db.GetMatchesByDivisionAndMatchday(ctx, division, matchday)
+	if err != nil {
+		return err
+	}
+	if !matchdayComplete(matches) {
+		return nil
+	}
+
+	posted, err := db.HasMatchdayRecap(ctx, division, matchday)
+	if err != nil || posted {
+		return err
+	}
+
+	teams, err := db.GetTeamsByDivision(ctx, division)
+	if err != nil {
+		return err
+	}
+	all, err := db.GetMatchesByDivision(ctx, division)
+	if err != nil {
+		return err
+	}
+
+	embed := recapEmbed(division, matchday, teams, matches, all)
+	send := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+
+	if file := resultsImage(division, matchday, matches, namesByID(teams)); file != nil {
+		attachImage(embed, file)
+		send.Files = []*discordgo.File{file}
+	}
+
+	msg, err := s.ChannelMessageSendComplex(channelID, send)
+	if err != nil {
+		return fmt.Errorf("fehler beim Posten des Rückblicks für Division %d, Woche %d: %w", division, matchday, err)
+	}
+
+	return db.SaveMatchdayRecap(ctx, division, matchday, channelID, msg.ID)
+}
+
+// matchdayComplete prüft, ob alle Matches eines Spieltags (außer Freilosen) ein Ergebnis haben
+func matchdayComplete(matches []*database.Match) bool {
+	played := 0
+	for _, match := range matches {
+		if match.IsBye() {
+			continue
+		}
+		if !match.IsPlayed() {
+			return false
+		}
+		played++
+	}
+	return played > 0
+}
+
+// recapEmbed baut den Rückblick eines Spieltags aus Ergebnissen, Überraschungen und Tabellenspitze
+func recapEmbed(division, matchday int, teams []*database.Team, matches, all []*database.Match) *discordgo.MessageEmbed {
+	names := namesByID(teams)
+
+	// Tabelle vor und nach dem Spieltag
+	var before, after []*database.Match
+	for _, match := range all {
+		if match.Matchday < matchday {
+			before = append(before, match)
+		}
+		if match.Matchday <= matchday {
+			after = append(after, match)
+		}
+	}
+	previous := standings.Calculate(teams, before)
+	current := standings.Calculate(teams, after)
+
+	var results, byes []string
+	for _, match := range matches {
+		if match.IsBye() {
+			byes = append(byes, names[match.TeamHomeID])
+			continue
+		}
+		results = append(results, fmt.Sprintf("%s **%d:%d** %s",
+			names[match.TeamHomeID], match.ScoreHome.Int64, match.ScoreAway.Int64, names[int(match.TeamAwayID.Int64)]))
+	}
+	if len(byes) > 0 {
+		results = append(results, "Spielfrei / Bye: "+strings.Join(byes, ", "))
+	}
+
+	var upsets []string
+	for _, u := range findUpsets(previous, matches) {
+		winner, loser := u.winnerLoser()
+		upsets = append(upsets, fmt.Sprintf("**%s** (Platz %d) schlägt %s (Platz %d)",
+			names[winner.TeamID], winner.Position, names[loser.TeamID], loser.Position))
+	}
+
+	var top []string
+	for _, row := range current {
+		if len(top) == recapTopTeams {
+			break
+		}
+		top = append(top, fmt.Sprintf("%d. **%s** – %d Pkt (%+d)", row.Position, row.Name, row.Points, row.GameDiff()))
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Ergebnisse / Results", Value: strings.Join(results, "\n")},
+	}
+	if len(upsets) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "⚡ Überraschungen / Upsets", Value: strings.Join(upsets, "\n")})
+	}
+	if len(top) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "🏆 Tabellenspitze / Top of the table", Value: strings.Join(top, "\n")})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🏁 Division %d – Woche %d abgeschlossen / Week %d complete", division, matchday, matchday),
+		Description: "Alle Matches dieser Woche sind gespielt!\nAll matches of this week have been played!",
+		Color:       0x5865F2,
+		Fields:      fields,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+}
+
+// findUpsets gibt die Matches eines Spieltags zurück, die ein in der Tabelle deutlich schlechter platziertes Team gewonnen hat.
+// Vor dem ersten Ergebnis der Saison gibt es noch keine aussagekräftige Tabelle und damit keine Überraschungen.
+func findUpsets(previous []*standings.Row, matches []*database.Match) []upset {
+	rows := make(map[int]*standings.Row, len(previous))
+	started := false
+	for _, row := range previous {
+		rows[row.TeamID] = row
+		if row.Played > 0 {
+			started = true
+		}
+	}
+	if !started {
+		return nil
+	}
+
+	var upsets []upset
+	for _, match := range matches {
+		if match.IsBye() || !match.IsPlayed() {
+			continue
+		}
+		home, away := rows[match.TeamHomeID], rows[int(match.TeamAwayID.Int64)]
+		if home == nil || away == nil {
+			continue
+		}
+
+		u := upset{home: home, away: away, homeWon: match.ScoreHome.Int64 > match.ScoreAway.Int64}
+		winner, loser := u.winnerLoser()
+		if winner.Position-loser.Position >= upsetGap {
+			upsets = append(upsets, u)
+		}
+	}
+	return upsets
+}
+
+// upset hält die Tabellenplätze beider Teams vor dem Spieltag fest
+type upset struct {
+	home, away *standings.Row
+	homeWon    bool
+}
+
+func (u upset) winnerLoser() (winner, loser *standings.Row) {
+	if u.homeWon {
+		return u.home, u.away
+	}
+	return u.away, u.home
+}
+
+// teamNames lädt die Teamnamen einer Division
+func teamNames(ctx context.Context, db database.TeamRepository, division int) (map[int]string, error) {
+	teams, err := db.GetTeamsByDivision(ctx, division)
+	if err != nil {
+		return nil, err
+	}
+	return namesByID(teams), nil
+}
+
+// namesByID ordnet den Team-IDs ihre Namen zu
+func namesByID(teams []*database.Team) map[int]string {
+	names := make(map[int]string, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+	return names
+}
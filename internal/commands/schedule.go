@@ -1,39 +1,59 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
 	"github.com/jamie/prestigeleagueseasonfour/internal/scheduler"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
 )
 
-// ScheduleCommand erstellt einen Spielplan für eine Division
-func ScheduleCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *database.Database) {
+const (
+	scheduleModeFull      = "full"
+	scheduleModeRemaining = "remaining"
+)
+
+// ScheduleCommand erstellt einen Spielplan für eine Division.
+// Bereits gespielte Matches oder Matches mit Channel werden nur mit force überschrieben;
+// im Modus "remaining" bleiben alle begonnenen Spieltage erhalten. Wiederholt der neue Plan
+// eine Paarung aus den beibehaltenen Spieltagen, wird er ebenfalls nur mit force gespeichert.
+func ScheduleCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
 	options := i.ApplicationCommandData().Options
-	if len(options) == 0 {
-		respondError(s, i, "Bitte gib eine Division an")
-		return
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	if optionMap["division"] == nil {
+		return errors.New("Bitte gib eine Division an")
 	}
 
-	division := int(options[0].IntValue())
+	division := int(optionMap["division"].IntValue())
+	mode := scheduleModeFull
+	if opt, ok := optionMap["mode"]; ok {
+		mode = opt.StringValue()
+	}
+	force := optionMap["force"] != nil && optionMap["force"].BoolValue()
+	dryRun := optionMap["dry_run"] != nil && optionMap["dry_run"].BoolValue()
+	db = db.WithActor(interactionUserID(i))
 
 	// Teams der Division abrufen
-	teams, err := db.GetTeamsByDivision(division)
+	teams, err := db.GetTeamsByDivision(ctx, division)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Abrufen der Teams: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Abrufen der Teams: %w", err)
 	}
 
 	if len(teams) == 0 {
-		respondError(s, i, fmt.Sprintf("Keine Teams in Division %d gefunden", division))
-		return
+		return fmt.Errorf("Keine Teams in Division %d gefunden", division)
 	}
 
 	if len(teams) < 3 {
-		respondError(s, i, fmt.Sprintf("Division %d hat zu wenige Teams (%d). Mindestens 3 Teams benötigt.", division, len(teams)))
-		return
+		return fmt.Errorf("Division %d hat zu wenige Teams (%d). Mindestens 3 Teams benötigt.", division, len(teams))
 	}
 
 	// Team-IDs extrahieren
@@ -47,45 +67,252 @@ func ScheduleCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *d
 	// Spielplan generieren
 	matchdays, err := scheduler.GenerateMatches(teamIDs)
 	if err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Generieren des Spielplans: %v", err))
-		return
+		return fmt.Errorf("Fehler beim Generieren des Spielplans: %w", err)
+	}
+
+	// Bestehende Matches prüfen
+	existing, err := db.GetMatchesByDivision(ctx, division)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der bestehenden Matches: %w", err)
+	}
+
+	fromMatchday := 1
+	if mode == scheduleModeRemaining {
+		fromMatchday = firstUntouchedMatchday(existing)
 	}
 
-	// Alte Matches löschen
-	if err := db.DeleteMatchesByDivision(division); err != nil {
-		respondError(s, i, fmt.Sprintf("Fehler beim Löschen alter Matches: %v", err))
-		return
+	var kept, toDelete, locked []*database.Match
+	for _, match := range existing {
+		if match.Matchday < fromMatchday {
+			kept = append(kept, match)
+			continue
+		}
+		toDelete = append(toDelete, match)
+		if matchTouched(match) {
+			locked = append(locked, match)
+		}
 	}
 
-	// Matches in Datenbank speichern
-	totalMatches := 0
+	// Neue Matches ab dem ersten offenen Spieltag planen
+	var plans []database.MatchPlan
 	for _, matchday := range matchdays {
 		for _, match := range matchday {
+			if match.Matchday < fromMatchday {
+				continue
+			}
+
+			// Eigene Variable pro Match, da match bei jedem Durchlauf überschrieben wird
 			var awayID *int
 			if match.TeamAwayID != 0 {
-				awayID = &match.TeamAwayID
+				away := match.TeamAwayID
+				awayID = &away
 			}
 
-			_, err := db.CreateMatch(division, match.Matchday, match.TeamHomeID, awayID)
-			if err != nil {
-				respondError(s, i, fmt.Sprintf("Fehler beim Erstellen des Matches: %v", err))
-				return
-			}
-			totalMatches++
+			plans = append(plans, database.MatchPlan{
+				Matchday:   match.Matchday,
+				TeamHomeID: match.TeamHomeID,
+				TeamAwayID: awayID,
+			})
+		}
+	}
+
+	if len(plans) == 0 {
+		return fmt.Errorf("Alle %d Spieltage von Division %d haben bereits begonnen, es gibt nichts neu zu erstellen", len(matchdays), division)
+	}
+
+	var orphaned []string
+	for _, match := range toDelete {
+		if match.ChannelID.Valid && match.ChannelID.String != "" {
+			orphaned = append(orphaned, fmt.Sprintf("<#%s>", match.ChannelID.String))
+		}
+	}
+
+	repeated := repeatedPairings(kept, plans, teamNames)
+
+	if dryRun {
+		embed := &discordgo.MessageEmbed{
+			Title: fmt.Sprintf("🔍 Vorschau: Spielplan für Division %d", division),
+			Description: fmt.Sprintf("**%d Teams**, Spieltage **%d-%d**\n\n🗑️ **%d Matches** würden gelöscht (%d mit Ergebnis oder Channel)\n➕ **%d Matches** würden erstellt\n\n",
+				len(teams), fromMatchday, len(matchdays), len(toDelete), len(locked), len(plans)),
+			Color:  0x0099ff,
+			Fields: schedulePreviewFields(matchdays, fromMatchday, teamNames),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "Dry-Run: Es wurde nichts geändert.",
+			},
 		}
+		if len(orphaned) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "⚠️ Channels ohne Match danach",
+				Value:  truncate(strings.Join(orphaned, " "), 1024),
+				Inline: false,
+			})
+		}
+		if len(locked) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "🔒 Würden gelöscht (Ergebnis oder Channel)",
+				Value:  truncate(strings.Join(lockedMatchLines(locked, teamNames), "\n"), 1024),
+				Inline: false,
+			})
+		}
+		if len(repeated) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "⚠️ Paarungen bereits angesetzt",
+				Value:  truncate(strings.Join(repeated, "\n"), 1024),
+				Inline: false,
+			})
+		}
+		if (len(locked) > 0 || len(repeated) > 0) && !force {
+			embed.Footer.Text = "Dry-Run: Es wurde nichts geändert. Zum Speichern wäre `force:True` nötig."
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return nil
+	}
+
+	if len(locked) > 0 && !force {
+		return fmt.Errorf(
+			"%d Matches in Division %d haben bereits ein Ergebnis oder einen Channel. "+
+				"Nutze `mode:remaining`, um nur die offenen Spieltage neu zu erstellen, `dry_run:True` für eine Vorschau oder `force:True`, um alles zu überschreiben.",
+			len(locked), division)
+	}
+
+	if len(repeated) > 0 && !force {
+		return errors.New(truncate(fmt.Sprintf(
+			"Der neue Spielplan wiederholt %d Paarungen aus den beibehaltenen Spieltagen von Division %d. "+
+				"Nutze `dry_run:True` für eine Vorschau oder `force:True`, um trotzdem neu zu planen.\n%s",
+			len(repeated), division, strings.Join(repeated, "\n")), 1900))
+	}
+
+	// Alte Matches ersetzen
+	err = db.WithEvent(leagueapi.EventScheduleGenerated, leagueapi.ScheduleGeneratedEvent{
+		Division:     division,
+		Mode:         mode,
+		FromMatchday: fromMatchday,
+		Matchdays:    len(matchdays),
+		Matches:      len(plans),
+	}).ReplaceMatches(ctx, division, fromMatchday, plans)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Spielplans: %w", err)
 	}
 
 	// Response erstellen
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("Spielplan für Division %d erstellt", division),
-		Description: fmt.Sprintf("**%d Teams**, **%d Spieltage**, **%d Matches**\n\n", len(teams), len(matchdays), totalMatches),
+		Description: fmt.Sprintf("**%d Teams**, **%d Spieltage**, **%d Matches**\n\n", len(teams), len(matchdays), len(plans)),
 		Color:       0x00ff00,
-		Fields:      []*discordgo.MessageEmbedField{},
+		Fields:      schedulePreviewFields(matchdays, fromMatchday, teamNames),
+	}
+
+	if fromMatchday > 1 {
+		embed.Description += fmt.Sprintf("Spieltage 1-%d wurden beibehalten.\n", fromMatchday-1)
+	}
+
+	if remaining := len(matchdays) - fromMatchday + 1; remaining > 3 {
+		embed.Description += fmt.Sprintf("*... und %d weitere Spieltage*", remaining-3)
+	}
+
+	if len(orphaned) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "⚠️ Channels ohne Match",
+			Value:  truncate(strings.Join(orphaned, " "), 1024),
+			Inline: false,
+		})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+	return nil
+}
+
+// matchTouched prüft ob ein Match bereits ein Ergebnis oder einen Channel hat
+func matchTouched(match *database.Match) bool {
+	return match.ScoreHome.Valid || match.ScoreAway.Valid || (match.ChannelID.Valid && match.ChannelID.String != "")
+}
+
+// firstUntouchedMatchday gibt den ersten Spieltag nach dem letzten begonnenen Spieltag zurück
+func firstUntouchedMatchday(matches []*database.Match) int {
+	last := 0
+	for _, match := range matches {
+		if matchTouched(match) && match.Matchday > last {
+			last = match.Matchday
+		}
+	}
+	return last + 1
+}
+
+// lockedMatchLines beschreibt Matches mit Ergebnis oder Channel für die Vorschau
+func lockedMatchLines(matches []*database.Match, teamNames map[int]string) []string {
+	lines := make([]string, 0, len(matches))
+	for _, match := range matches {
+		awayName := "Free Win"
+		if !match.IsBye() {
+			awayName = teamNames[int(match.TeamAwayID.Int64)]
+		}
+
+		line := fmt.Sprintf("• Woche %d: %s vs %s", match.Matchday, teamNames[match.TeamHomeID], awayName)
+		if match.IsPlayed() {
+			line += fmt.Sprintf(" (%d:%d)", match.ScoreHome.Int64, match.ScoreAway.Int64)
+		}
+		if match.ChannelID.Valid && match.ChannelID.String != "" {
+			line += fmt.Sprintf(" <#%s>", match.ChannelID.String)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// repeatedPairings gibt die geplanten Paarungen zurück, die es in den beibehaltenen Spieltagen schon gibt.
+// Im Modus "remaining" wird der Rest des Spielplans neu generiert; hat sich die Division seitdem
+// verändert, passt die neue Vorlage nicht mehr zu den bereits gespielten Spieltagen.
+func repeatedPairings(kept []*database.Match, plans []database.MatchPlan, teamNames map[int]string) []string {
+	playedIn := make(map[[2]int]int)
+	for _, match := range kept {
+		if match.IsBye() {
+			continue
+		}
+		playedIn[pairing(match.TeamHomeID, int(match.TeamAwayID.Int64))] = match.Matchday
+	}
+
+	var lines []string
+	for _, plan := range plans {
+		if plan.TeamAwayID == nil {
+			continue
+		}
+		if matchday, ok := playedIn[pairing(plan.TeamHomeID, *plan.TeamAwayID)]; ok {
+			lines = append(lines, fmt.Sprintf("• Woche %d: %s vs %s (bereits in Woche %d)",
+				plan.Matchday, teamNames[plan.TeamHomeID], teamNames[*plan.TeamAwayID], matchday))
+		}
+	}
+	return lines
+}
+
+// pairing gibt die Paarung zweier Teams unabhängig von Heim und Auswärts zurück
+func pairing(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
 	}
+	return [2]int{a, b}
+}
+
+// schedulePreviewFields erstellt Embed-Felder für die ersten 3 Spieltage ab fromMatchday
+func schedulePreviewFields(matchdays [][]scheduler.Match, fromMatchday int, teamNames map[int]string) []*discordgo.MessageEmbedField {
+	fields := []*discordgo.MessageEmbedField{}
 
-	// Erste 3 Spieltage als Preview
 	for mdIdx, matchday := range matchdays {
-		if mdIdx >= 3 {
+		if mdIdx+1 < fromMatchday {
+			continue
+		}
+		if len(fields) >= 3 {
 			break
 		}
 
@@ -99,31 +326,23 @@ func ScheduleCommand(s *discordgo.Session, i *discordgo.InteractionCreate, db *d
 			matchList = append(matchList, fmt.Sprintf("• %s vs %s", homeName, awayName))
 		}
 
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   fmt.Sprintf("Spieltag %d", mdIdx+1),
 			Value:  strings.Join(matchList, "\n"),
 			Inline: false,
 		})
 	}
 
-	if len(matchdays) > 3 {
-		embed.Description += fmt.Sprintf("*... und %d weitere Spieltage*", len(matchdays)-3)
-	}
-
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return fields
 }
 
-func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: "❌ " + message,
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
-	})
+// interactionUserID gibt die Discord User-ID des ausführenden Users zurück
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return database.SystemActor
 }
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+)
+
+// StandingsCommand zeigt die aktuelle Tabelle einer Division an (als Grafik, sofern verfügbar)
+func StandingsCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	division := int(optionMap["division"].IntValue())
+
+	// Das Zeichnen der Grafik kann etwas dauern
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	rows, err := standings.ForDivision(ctx, db, division)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Berechnen der Tabelle: %w", err)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("Division %d hat keine Teams", division)
+	}
+
+	embed := standingsEmbed(division, rows)
+	edit := &discordgo.WebhookEdit{Embeds: &[]*discordgo.MessageEmbed{embed}}
+
+	// Die Grafik ersetzt die Text-Tabelle, die bei 10 Teams in Discord schwer lesbar ist
+	if file := standingsImage(division, rows); file != nil {
+		embed.Description = ""
+		attachImage(embed, file)
+		edit.Files = []*discordgo.File{file}
+	}
+
+	s.InteractionResponseEdit(i.Interaction, edit)
+	return nil
+}
+
+// UpdateStandingsMessage aktualisiert die angepinnte Tabellen-Nachricht einer Division.
+// Existiert noch keine Nachricht im Channel oder wurde sie gelöscht, wird sie neu erstellt.
+func UpdateStandingsMessage(ctx context.Context, s discord.Session, db database.Store, channelID string, division int) error {
+	rows, err := standings.ForDivision(ctx, db, division)
+	if err != nil {
+		return err
+	}
+
+	embed := standingsEmbed(division, rows)
+
+	stored, err := db.GetStandingsMessage(ctx, division)
+	if err != nil {
+		return err
+	}
+
+	// Bestehende Nachricht bearbeiten, solange sie im konfigurierten Channel liegt
+	if stored != nil && stored.ChannelID == channelID {
+		_, err := s.ChannelMessageEditEmbed(channelID, stored.MessageID, embed)
+		if err == nil {
+			return nil
+		}
+		if !isUnknownMessage(err) {
+			return fmt.Errorf("fehler beim Aktualisieren der Tabelle für Division %d: %w", division, err)
+		}
+		log.Printf("[Standings] Nachricht für Division %d wurde gelöscht, erstelle sie neu", division)
+	}
+
+	msg, err := s.ChannelMessageSendEmbed(channelID, embed)
+	if err != nil {
+		return fmt.Errorf("fehler beim Senden der Tabelle für Division %d: %w", division, err)
+	}
+
+	if err := s.ChannelMessagePin(channelID, msg.ID); err != nil {
+		log.Printf("[Standings] Tabelle für Division %d konnte nicht angepinnt werden: %v", division, err)
+	}
+
+	return db.SaveStandingsMessage(ctx, division, channelID, msg.ID)
+}
+
+// standingsEmbed formatiert die Tabelle einer Division als Embed
+func standingsEmbed(division int, rows []*standings.Row) *discordgo.MessageEmbed {
+	var b strings.Builder
+	b.WriteString("```\n")
+	b.WriteString(fmt.Sprintf("%-3s %-20s %3s %3s %3s %7s %4s %4s\n", "#", "Team", "Sp", "S", "N", "Spiele", "Diff", "Pkt"))
+	for _, row := range rows {
+		name := row.Name
+		switch {
+		case row.IsDisqualified:
+			name += " (DQ)"
+		case row.IsWithdrawn:
+			name += " (zg.)"
+		}
+		b.WriteString(fmt.Sprintf("%-3d %-20s %3d %3d %3d %3d:%-3d %+4d %4d\n",
+			row.Position, truncate(name, 20), row.Played, row.Wins, row.Losses,
+			row.GamesWon, row.GamesLost, row.GameDiff(), row.Points))
+	}
+	b.WriteString("```")
+
+	description := b.String()
+	if len(rows) == 0 {
+		description = "Noch keine Teams in dieser Division."
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📊 Tabelle Division %d / Standings Division %d", division, division),
+		Description: description,
+		Color:       0x5865F2,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d Punkte pro Sieg • DQ = disqualifiziert, zg. = zurückgezogen • Zuletzt aktualisiert", standings.PointsPerWin),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// isUnknownMessage prüft, ob Discord die Nachricht nicht (mehr) kennt
+func isUnknownMessage(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownMessage
+}
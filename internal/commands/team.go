@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+)
+
+// formLength ist die Anzahl der letzten Ergebnisse, die /team als Form anzeigt
+const formLength = 5
+
+// TeamCommand zeigt Division, Roster, Bilanz, Form und das nächste Match eines Teams an
+func TeamCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	team, err := teamFromOptions(ctx, db, optionMap)
+	if err != nil {
+		return fmt.Errorf("Team konnte nicht gefunden werden: %w", err)
+	}
+
+	players, err := db.GetPlayersByTeam(ctx, team.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen des Rosters: %w", err)
+	}
+
+	matches, err := db.GetMatchesByTeam(ctx, team.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Matches: %w", err)
+	}
+
+	rows, err := standings.ForDivision(ctx, db, team.Division)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Berechnen der Tabelle: %w", err)
+	}
+
+	names, err := teamNames(ctx, db, team.Division)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Teams: %w", err)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{teamEmbed(team, players, matches, rows, names)},
+		},
+	})
+	return nil
+}
+
+// teamFromOptions sucht das Team anhand der Option team (Rolle) oder name
+func teamFromOptions(ctx context.Context, db database.TeamRepository, optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption) (*database.Team, error) {
+	if opt, ok := optionMap["team"]; ok {
+		team, err := db.GetTeamByRoleID(ctx, opt.RoleValue(nil, "").ID)
+		if err != nil {
+			return nil, fmt.Errorf("kein Team mit dieser Rolle gefunden: %w", err)
+		}
+		return team, nil
+	}
+
+	if opt, ok := optionMap["name"]; ok {
+		return teamByName(ctx, db, opt.StringValue())
+	}
+
+	return nil, errors.New("bitte gib eine Team-Rolle oder einen Teamnamen an")
+}
+
+// teamByName sucht ein Team anhand des Namens, bei Bedarf ohne Beachtung der Groß-/Kleinschreibung
+func teamByName(ctx context.Context, db database.TeamRepository, name string) (*database.Team, error) {
+	name = strings.TrimSpace(name)
+	if team, err := db.GetTeamByName(ctx, name); err == nil {
+		return team, nil
+	}
+
+	teams, err := db.GetAllTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		if strings.EqualFold(team.Name, name) {
+			return team, nil
+		}
+	}
+
+	return nil, fmt.Errorf("team '%s' nicht gefunden", name)
+}
+
+// teamEmbed baut die Übersicht eines Teams
+func teamEmbed(team *database.Team, players []*database.Player, matches []*database.Match, rows []*standings.Row, names map[int]string) *discordgo.MessageEmbed {
+	var row *standings.Row
+	for _, r := range rows {
+		if r.TeamID == team.ID {
+			row = r
+		}
+	}
+
+	status := "✅ Aktiv / Active"
+	color := 0x5865F2
+	switch {
+	case team.IsDisqualified:
+		status = "⛔ Disqualifiziert / Disqualified"
+		if team.DisqualifiedAt.Valid {
+			status += " (" + team.DisqualifiedAt.Time.Format("02.01.2006") + ")"
+		}
+		color = 0xED4245
+	case team.IsWithdrawn:
+		status = "🚪 Zurückgezogen / Withdrawn"
+		if team.WithdrawnAt.Valid {
+			status += " (" + team.WithdrawnAt.Time.Format("02.01.2006") + ")"
+		}
+		color = 0x99AAB5
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Division", Value: fmt.Sprintf("%d", team.Division), Inline: true},
+		{Name: "Status", Value: status, Inline: true},
+	}
+
+	if row != nil {
+		fields = append(fields,
+			&discordgo.MessageEmbedField{
+				Name:   "Platz / Position",
+				Value:  fmt.Sprintf("%d. von %d", row.Position, len(rows)),
+				Inline: true,
+			},
+			&discordgo.MessageEmbedField{
+				Name: "Bilanz / Record",
+				Value: fmt.Sprintf("%d S – %d N • Spiele %d:%d (%+d) • %d Pkt",
+					row.Wins, row.Losses, row.GamesWon, row.GamesLost, row.GameDiff(), row.Points),
+			},
+		)
+	}
+
+	fields = append(fields,
+		&discordgo.MessageEmbedField{Name: "Form (letzte 5)", Value: teamForm(team.ID, matches)},
+		&discordgo.MessageEmbedField{Name: "Nächstes Match / Next match", Value: nextMatch(team.ID, matches, names)},
+		&discordgo.MessageEmbedField{Name: fmt.Sprintf("Roster (%d)", len(players)), Value: rosterList(players)},
+	)
+
+	return &discordgo.MessageEmbed{
+		Title:  "🛡️ " + team.Name,
+		Color:  color,
+		Fields: fields,
+	}
+}
+
+// teamForm gibt die letzten Ergebnisse als W/L zurück (älteste zuerst). Freilose zählen nicht.
+func teamForm(teamID int, matches []*database.Match) string {
+	var form []string
+	for _, match := range matches {
+		if match.IsBye() || !match.IsPlayed() {
+			continue
+		}
+		won := match.ScoreHome.Int64 > match.ScoreAway.Int64
+		if match.TeamHomeID != teamID {
+			won = !won
+		}
+		if won {
+			form = append(form, "🟩 W")
+		} else {
+			form = append(form, "🟥 L")
+		}
+	}
+
+	if len(form) == 0 {
+		return "Noch keine Ergebnisse"
+	}
+	if len(form) > formLength {
+		form = form[len(form)-formLength:]
+	}
+	return strings.Join(form, "  ")
+}
+
+// nextMatch beschreibt das nächste noch offene Match des Teams
+func nextMatch(teamID int, matches []*database.Match, names map[int]string) string {
+	for _, match := range matches {
+		if match.IsPlayed() {
+			continue
+		}
+		if match.IsBye() {
+			return fmt.Sprintf("Woche %d: spielfrei / bye", match.Matchday)
+		}
+
+		opponentID := int(match.TeamAwayID.Int64)
+		if opponentID == teamID {
+			opponentID = match.TeamHomeID
+		}
+
+		text := fmt.Sprintf("Woche %d: vs **%s**", match.Matchday, names[opponentID])
+		if match.ScheduledAt.Valid {
+			text += fmt.Sprintf("\n🗓️ <t:%d:F>", match.ScheduledAt.Time.Unix())
+		}
+		if match.ChannelID.Valid && match.ChannelID.String != "" {
+			text += fmt.Sprintf("\n💬 <#%s>", match.ChannelID.String)
+		}
+		return text
+	}
+
+	return "Keine offenen Matches"
+}
+
+// rosterList listet die Spieler mit Tracker-Link auf
+func rosterList(players []*database.Player) string {
+	if len(players) == 0 {
+		return "Kein Roster hinterlegt"
+	}
+
+	lines := make([]string, 0, len(players))
+	for _, player := range players {
+		if player.TrackerURL != "" {
+			lines = append(lines, fmt.Sprintf("• [%s](%s)", player.Name, player.TrackerURL))
+		} else {
+			lines = append(lines, "• "+player.Name)
+		}
+	}
+	return truncate(strings.Join(lines, "\n"), 1024)
+}
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/channels"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// WithdrawCommand zieht ein Team zurück und wandelt seine offenen Matches in Freilose für die Gegner um
+func WithdrawCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	db = db.WithActor(interactionUserID(i))
+
+	// Team anhand der Rolle oder des Namens finden
+	team, err := teamFromOptions(ctx, db, optionMap)
+	if err != nil {
+		return fmt.Errorf("Team konnte nicht gefunden werden: %w", err)
+	}
+
+	if team.IsWithdrawn {
+		return fmt.Errorf("Team **%s** ist bereits zurückgezogen", team.Name)
+	}
+
+	// Defer Antwort, da Channels angepasst werden
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	changed, removed, err := db.WithdrawTeam(ctx, team.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Zurückziehen des Teams: %w", err)
+	}
+
+	var errorLog []string
+
+	// Match-Channels der Gegner aktualisieren
+	for _, match := range changed {
+		if !match.ChannelID.Valid || match.ChannelID.String == "" {
+			continue
+		}
+
+		opponent, err := db.GetTeamByID(ctx, match.TeamHomeID)
+		if err != nil {
+			errorLog = append(errorLog, fmt.Sprintf("Match ID %d: %v", match.ID, err))
+			continue
+		}
+
+		if err := channels.AnnounceWithdrawal(s, match.ChannelID.String, team, opponent); err != nil {
+			errorLog = append(errorLog, fmt.Sprintf("Match ID %d: %v", match.ID, err))
+		}
+	}
+
+	// Channels entfallener Freilose löschen
+	for _, match := range removed {
+		if !match.ChannelID.Valid || match.ChannelID.String == "" {
+			continue
+		}
+
+		if _, err := s.ChannelDelete(match.ChannelID.String); err != nil {
+			errorLog = append(errorLog, fmt.Sprintf("Match ID %d: Channel konnte nicht gelöscht werden: %v", match.ID, err))
+		}
+	}
+
+	for _, msg := range errorLog {
+		log.Println("[Withdraw]", msg)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🏳️ Team zurückgezogen",
+		Description: fmt.Sprintf("**%s** wurde aus dem Spielbetrieb zurückgezogen.", team.Name),
+		Color:       0xFFA500,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Auswirkungen",
+				Value:  fmt.Sprintf("• %d offene Matches wurden zu Free Wins für die Gegner\n• %d Freilose des Teams sind entfallen\n• Die Matches werden nicht in der Tabelle gewertet", len(changed), len(removed)),
+				Inline: false,
+			},
+		},
+	}
+
+	if len(errorLog) > 0 {
+		embed.Color = 0xffaa00
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "❌ Fehler bei Channels",
+			Value:  truncate(strings.Join(errorLog, "\n"), 1024),
+			Inline: false,
+		})
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	return nil
+}
+
+// LateEntryCommand setzt ein nachgemeldetes Team in den freien Platz eines Spielplans ein
+func LateEntryCommand(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate, db database.Store) error {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	name := strings.TrimSpace(optionMap["name"].StringValue())
+	division := int(optionMap["division"].IntValue())
+	roleID := optionMap["role"].RoleValue(nil, "").ID
+	db = db.WithActor(interactionUserID(i))
+
+	matches, err := db.GetMatchesByDivision(ctx, division)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der Matches: %w", err)
+	}
+
+	if len(matches) == 0 {
+		return fmt.Errorf("Division %d hat noch keinen Spielplan. Nutze `/schedule`.", division)
+	}
+
+	fromMatchday := firstUnplayedMatchday(matches)
+	if opt, ok := optionMap["from_matchday"]; ok {
+		fromMatchday = int(opt.IntValue())
+	}
+
+	// Defer Antwort, da Channels angepasst werden
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	// Bestehendes Team verwenden oder neu anlegen und in einem Schritt einsetzen
+	team, changed, err := db.LateEntry(ctx, name, division, roleID, fromMatchday)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Einsetzen des Teams: %w", err)
+	}
+
+	var errorLog []string
+	var lines []string
+	for _, match := range changed {
+		homeTeam, err := db.GetTeamByID(ctx, match.TeamHomeID)
+		if err != nil {
+			errorLog = append(errorLog, fmt.Sprintf("Match ID %d: %v", match.ID, err))
+			continue
+		}
+		awayTeam, err := db.GetTeamByID(ctx, int(match.TeamAwayID.Int64))
+		if err != nil {
+			errorLog = append(errorLog, fmt.Sprintf("Match ID %d: %v", match.ID, err))
+			continue
+		}
+
+		line := fmt.Sprintf("• Woche %d: %s vs %s", match.Matchday, homeTeam.Name, awayTeam.Name)
+
+		// Bestehenden Freilos-Channel zum Match-Channel machen
+		if match.ChannelID.Valid && match.ChannelID.String != "" {
+			if err := channels.AddTeamToMatchChannel(s, match.ChannelID.String, match, homeTeam, awayTeam, team); err != nil {
+				errorLog = append(errorLog, fmt.Sprintf("Match ID %d: %v", match.ID, err))
+			}
+			line += fmt.Sprintf(" (<#%s>)", match.ChannelID.String)
+		}
+		lines = append(lines, line)
+	}
+
+	for _, msg := range errorLog {
+		log.Println("[LateEntry]", msg)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "➕ Team nachgemeldet",
+		Description: fmt.Sprintf("**%s** spielt ab **Woche %d** in Division %d.", team.Name, fromMatchday, division),
+		Color:       0x00ff00,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   fmt.Sprintf("Matches (%d)", len(changed)),
+				Value:  truncate(strings.Join(lines, "\n"), 1024),
+				Inline: false,
+			},
+		},
+	}
+
+	if len(errorLog) > 0 {
+		embed.Color = 0xffaa00
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "❌ Fehler bei Channels",
+			Value:  truncate(strings.Join(errorLog, "\n"), 1024),
+			Inline: false,
+		})
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	return nil
+}
+
+// firstUnplayedMatchday gibt den ersten Spieltag nach dem letzten Spieltag mit Ergebnis zurück
+func firstUnplayedMatchday(matches []*database.Match) int {
+	last := 0
+	for _, match := range matches {
+		if match.IsPlayed() && match.Matchday > last {
+			last = match.Matchday
+		}
+	}
+	return last + 1
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SystemActor wird als Akteur eingetragen, wenn keine Discord-ID bekannt ist
+const SystemActor = "system"
+
+// AuditEntry repräsentiert einen Eintrag im Audit-Log
+type AuditEntry struct {
+	ID         int
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   int
+	Before     sql.NullString
+	After      sql.NullString
+	Reason     sql.NullString
+	CreatedAt  time.Time
+}
+
+// Divisions gibt die Divisionen zurück, deren Zustand der Eintrag verändert hat
+func (e *AuditEntry) Divisions() []int {
+	if e.EntityType == "division" {
+		return []int{e.EntityID}
+	}
+
+	seen := make(map[int]bool)
+	var divisions []int
+	for _, state := range []sql.NullString{e.Before, e.After} {
+		if !state.Valid {
+			continue
+		}
+
+		// Zustände sind einzelne Objekte oder Listen (matches.delete)
+		var items []struct {
+			Division int `json:"division"`
+		}
+		if err := json.Unmarshal([]byte(state.String), &items); err != nil {
+			var item struct {
+				Division int `json:"division"`
+			}
+			if json.Unmarshal([]byte(state.String), &item) != nil {
+				continue
+			}
+			items = append(items, item)
+		}
+
+		for _, item := range items {
+			if item.Division != 0 && !seen[item.Division] {
+				seen[item.Division] = true
+				divisions = append(divisions, item.Division)
+			}
+		}
+	}
+
+	return divisions
+}
+
+// WithActor gibt eine Kopie der Datenbank zurück, die alle Änderungen dem angegebenen Discord-User zuordnet
+func (d *Database) WithActor(actorID string) Store {
+	c := *d
+	c.actor = actorID
+	return &c
+}
+
+// WithReason gibt eine Kopie der Datenbank zurück, die allen Audit-Einträgen eine Begründung mitgibt
+func (d *Database) WithReason(reason string) Store {
+	c := *d
+	c.reason = reason
+	return &c
+}
+
+// SetAuditHook registriert eine Funktion, die nach jedem gespeicherten Audit-Eintrag aufgerufen wird
+func (d *Database) SetAuditHook(hook func(*AuditEntry)) {
+	d.auditHook = hook
+}
+
+// actorOr gibt den gesetzten Akteur zurück oder den Fallback, falls keiner gesetzt ist
+func (d *Database) actorOr(fallback string) string {
+	if d.actor != "" {
+		return d.actor
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return SystemActor
+}
+
+// recordAudit schreibt einen Audit-Eintrag innerhalb der übergebenen Transaktion
+func (d *Database) recordAudit(q querier, actorID, action, entityType string, entityID int, before, after any) (*AuditEntry, error) {
+	entry := &AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Reason:     sql.NullString{String: d.reason, Valid: d.reason != ""},
+		CreatedAt:  time.Now(),
+	}
+
+	var err error
+	if entry.Before, err = auditJSON(before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = auditJSON(after); err != nil {
+		return nil, err
+	}
+
+	entry.ID, err = insertID(q,
+		`INSERT INTO audit_log (actor_id, action, entity_type, entity_id, before_json, after_json, reason)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, entry.Before, entry.After, entry.Reason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Schreiben des Audit-Logs: %w", err)
+	}
+
+	return entry, nil
+}
+
+// publishAudit ruft den Audit-Hook für bereits committete Einträge auf
+func (d *Database) publishAudit(entries ...*AuditEntry) {
+	if d.auditHook == nil {
+		return
+	}
+	for _, entry := range entries {
+		d.auditHook(entry)
+	}
+}
+
+func auditJSON(v any) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("fehler beim Serialisieren des Audit-Zustands: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// teamAuditState ist die JSON-Darstellung eines Teams im Audit-Log
+type teamAuditState struct {
+	Name           string `json:"name"`
+	Division       int    `json:"division"`
+	RoleID         string `json:"role_id"`
+	IsDisqualified bool   `json:"is_disqualified"`
+	IsWithdrawn    bool   `json:"is_withdrawn"`
+}
+
+func teamState(t *Team) any {
+	if t == nil {
+		return nil
+	}
+	return teamAuditState{
+		Name:           t.Name,
+		Division:       t.Division,
+		RoleID:         t.RoleID,
+		IsDisqualified: t.IsDisqualified,
+		IsWithdrawn:    t.IsWithdrawn,
+	}
+}
+
+// matchAuditState ist die JSON-Darstellung eines Matches im Audit-Log
+type matchAuditState struct {
+	Division    int        `json:"division"`
+	Matchday    int        `json:"matchday"`
+	TeamHomeID  int        `json:"team_home_id"`
+	TeamAwayID  *int64     `json:"team_away_id"`
+	ScoreHome   *int64     `json:"score_home"`
+	ScoreAway   *int64     `json:"score_away"`
+	ChannelID   string     `json:"channel_id,omitempty"`
+	ReportedBy  string     `json:"reported_by,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+func matchState(m *Match) any {
+	if m == nil {
+		return nil
+	}
+	state := matchAuditState{
+		Division:   m.Division,
+		Matchday:   m.Matchday,
+		TeamHomeID: m.TeamHomeID,
+		TeamAwayID: nullInt(m.TeamAwayID),
+		ScoreHome:  nullInt(m.ScoreHome),
+		ScoreAway:  nullInt(m.ScoreAway),
+		ChannelID:  m.ChannelID.String,
+		ReportedBy: m.ReportedBy.String,
+	}
+	if m.ScheduledAt.Valid {
+		scheduledAt := m.ScheduledAt.Time.UTC()
+		state.ScheduledAt = &scheduledAt
+	}
+	return state
+}
+
+func matchStates(matches []*Match) any {
+	states := make([]matchAuditState, 0, len(matches))
+	for _, m := range matches {
+		states = append(states, matchState(m).(matchAuditState))
+	}
+	return states
+}
+
+func nullInt(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
+
+// GetAuditLogByMatch ruft die Änderungshistorie eines Matches ab (neueste zuerst)
+func (d *Database) GetAuditLogByMatch(ctx context.Context, matchID, limit int) ([]*AuditEntry, error) {
+	return d.queryAuditLog(ctx,
+		`SELECT id, actor_id, action, entity_type, entity_id, before_json, after_json, reason, created_at
+		 FROM audit_log WHERE entity_type = 'match' AND entity_id = ?
+		 ORDER BY id DESC LIMIT ?`,
+		matchID, limit,
+	)
+}
+
+// GetAuditLogByTeam ruft die Änderungshistorie eines Teams und seiner Matches ab (neueste zuerst)
+func (d *Database) GetAuditLogByTeam(ctx context.Context, teamID, limit int) ([]*AuditEntry, error) {
+	return d.queryAuditLog(ctx,
+		`SELECT id, actor_id, action, entity_type, entity_id, before_json, after_json, reason, created_at
+		 FROM audit_log
+		 WHERE (entity_type = 'team' AND entity_id = ?)
+		    OR (entity_type = 'match' AND entity_id IN (
+		        SELECT id FROM matches WHERE team_home_id = ? OR team_away_id = ?))
+		 ORDER BY id DESC LIMIT ?`,
+		teamID, teamID, teamID, limit,
+	)
+}
+
+func (d *Database) queryAuditLog(ctx context.Context, query string, args ...any) ([]*AuditEntry, error) {
+	entries, err := queryAll(d.with(ctx), scanAuditEntry, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen des Audit-Logs: %w", err)
+	}
+	return entries, nil
+}
+
+func scanAuditEntry(row rowScanner) (*AuditEntry, error) {
+	entry := &AuditEntry{}
+	err := row.Scan(
+		&entry.ID, &entry.ActorID, &entry.Action, &entry.EntityType, &entry.EntityID,
+		&entry.Before, &entry.After, &entry.Reason, &entry.CreatedAt,
+	)
+	return entry, err
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// backupPrefix und backupLayout bilden die Dateinamen der Sicherungen in UTC, z.B.
+	// league-20260302-193000.250.db. Die Millisekunden trennen /backup, geplante Sicherungen und
+	// migrate backup in derselben Sekunde. Ältere Sicherungen ohne Millisekunden werden weiter erkannt,
+	// weil time.Parse Sekundenbruchteile nach backupParseLayout auch ohne Angabe im Layout akzeptiert.
+	backupPrefix      = "league-"
+	backupLayout      = "20060102-150405.000"
+	backupParseLayout = "20060102-150405"
+	backupSuffix      = ".db"
+)
+
+// errBackupDialect wird zurückgegeben, wenn eine Sicherung für PostgreSQL angefordert wird
+var errBackupDialect = errors.New("sicherungen sind nur für sqlite möglich, für postgresql bitte pg_dump verwenden")
+
+// Backup schreibt eine konsistente Kopie der Datenbank nach path. Die Kopie nutzt die
+// Online-Backup-API von SQLite, Bot und API können währenddessen weiter lesen und schreiben.
+func (d *Database) Backup(ctx context.Context, path string) error {
+	if d.dialect != SQLite {
+		return errBackupDialect
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("sicherung %s existiert bereits", path)
+	}
+
+	dst, err := sql.Open(SQLite.driver(), path)
+	if err != nil {
+		return fmt.Errorf("fehler beim Anlegen der Sicherung: %w", err)
+	}
+	defer dst.Close()
+
+	if err := copyPages(ctx, dst, d.DB); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("fehler beim Sichern der Datenbank: %w", err)
+	}
+
+	// Die Sicherung soll eine einzelne Datei ohne -wal und -shm bleiben, auch wenn sie geöffnet wird
+	if _, err := dst.ExecContext(ctx, "PRAGMA journal_mode = DELETE"); err != nil {
+		return fmt.Errorf("fehler beim Abschließen der Sicherung: %w", err)
+	}
+	return nil
+}
+
+// Restore überschreibt die Datenbank mit der Sicherung aus path. Die Sicherung wird vorher auf
+// Beschädigungen geprüft, danach werden fehlende Spalten wie beim Start ergänzt.
+// Andere Prozesse sollten die Datenbank währenddessen nicht verwenden.
+func (d *Database) Restore(ctx context.Context, path string) error {
+	if d.dialect != SQLite {
+		return errBackupDialect
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("sicherung %s nicht gefunden: %w", path, err)
+	}
+
+	src, err := sql.Open(SQLite.driver(), "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("fehler beim Öffnen der Sicherung: %w", err)
+	}
+	defer src.Close()
+
+	if err := checkBackup(ctx, src); err != nil {
+		return err
+	}
+
+	if err := copyPages(ctx, d.writer, src); err != nil {
+		return fmt.Errorf("fehler beim Wiederherstellen der Datenbank: %w", err)
+	}
+
+	if err := d.initSchema(); err != nil {
+		return fmt.Errorf("fehler beim Aktualisieren des Schemas der Sicherung: %w", err)
+	}
+	return nil
+}
+
+// checkBackup prüft, ob eine Datei eine unbeschädigte Datenbank dieser Liga ist
+func checkBackup(ctx context.Context, db *sql.DB) error {
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("sicherung ist keine gültige sqlite-datenbank: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("sicherung ist beschädigt: %s", result)
+	}
+
+	for _, table := range []string{"teams", "matches"} {
+		var count int
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("fehler beim Prüfen der Sicherung: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("sicherung enthält keine tabelle %s", table)
+		}
+	}
+	return nil
+}
+
+// copyPages kopiert die komplette Datenbank von src nach dst in einem Schritt
+func copyPages(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			return retryBusy(ctx, func() error {
+				backup, err := dstRaw.(*sqlite3.SQLiteConn).Backup("main", srcRaw.(*sqlite3.SQLiteConn), "main")
+				if err != nil {
+					return err
+				}
+				if _, err := backup.Step(-1); err != nil {
+					backup.Finish()
+					return err
+				}
+				return backup.Finish()
+			})
+		})
+	})
+}
+
+// CreateBackup legt eine Sicherung mit Zeitstempel im Verzeichnis dir an und löscht danach
+// alle bis auf die keep neuesten Sicherungen (keep <= 0 = nichts löschen).
+// Gibt es in derselben Millisekunde schon eine Sicherung, gilt sie als die neue.
+// Zurückgegeben wird der Pfad der neuen Sicherung.
+func (d *Database) CreateBackup(ctx context.Context, dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("fehler beim Anlegen des Sicherungsverzeichnisses: %w", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format(backupLayout)+backupSuffix)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := d.Backup(ctx, path); err != nil {
+		return "", err
+	}
+
+	if keep > 0 {
+		if _, err := PruneBackups(dir, keep); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// ListBackups gibt die Sicherungen im Verzeichnis dir zurück, die älteste zuerst
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fehler beim Lesen des Sicherungsverzeichnisses: %w", err)
+	}
+
+	type backup struct {
+		path    string
+		created time.Time
+	}
+	var found []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+		created, err := time.Parse(backupParseLayout, stamp)
+		if err != nil {
+			continue
+		}
+		found = append(found, backup{filepath.Join(dir, name), created})
+	}
+
+	// Nach Zeitpunkt statt alphabetisch, damit Namen mit und ohne Millisekunden richtig einsortiert werden
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].created.Before(found[j].created)
+	})
+
+	backups := make([]string, 0, len(found))
+	for _, b := range found {
+		backups = append(backups, b.path)
+	}
+	return backups, nil
+}
+
+// PruneBackups löscht alle bis auf die keep neuesten Sicherungen und gibt die gelöschten Pfade zurück
+func PruneBackups(dir string, keep int) ([]string, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, path := range backups[:len(backups)-keep] {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("fehler beim Löschen der Sicherung %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// IsBye prüft ob ein Match ein Freilos ist (die Auswärtsseite ist leer)
+func (m *Match) IsBye() bool {
+	return !m.TeamAwayID.Valid
+}
+
+// IsPlayed prüft ob für ein Match bereits ein Ergebnis eingetragen ist
+func (m *Match) IsPlayed() bool {
+	return m.ScoreHome.Valid && m.ScoreAway.Valid
+}
+
+// WithdrawTeam zieht ein Team aus dem laufenden Spielbetrieb zurück.
+// Alle noch nicht gespielten Matches des Teams werden zu Freilosen für die Gegner,
+// ohne dass das Team eine 0:3 Niederlage erhält. Eigene Freilose des Teams entfallen.
+// Zurückgegeben werden die geänderten und die gelöschten Matches.
+func (d *Database) WithdrawTeam(ctx context.Context, teamID int) ([]*Match, []*Match, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := getTeamByID(tx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if before.IsWithdrawn {
+		return nil, nil, fmt.Errorf("team %s ist bereits zurückgezogen", before.Name)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE teams SET is_withdrawn = TRUE, withdrawn_at = CURRENT_TIMESTAMP WHERE id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fehler beim Zurückziehen des Teams: %w", err)
+	}
+
+	entry, err := d.auditTeamChange(tx, "team.withdraw", before)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := []*AuditEntry{entry}
+
+	openMatches, err := queryMatches(tx,
+		"WHERE (team_home_id = ? OR team_away_id = ?) AND (score_home IS NULL OR score_away IS NULL) ORDER BY matchday, id",
+		teamID, teamID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changed, removed []*Match
+	for _, match := range openMatches {
+		if match.IsBye() {
+			// Freilos des zurückgezogenen Teams entfällt ersatzlos
+			if _, err := tx.Exec("DELETE FROM disqualified_matches WHERE match_id = ?", match.ID); err != nil {
+				return nil, nil, fmt.Errorf("fehler beim Löschen der gesicherten Match-Zustände: %w", err)
+			}
+			if _, err := tx.Exec("DELETE FROM matches WHERE id = ?", match.ID); err != nil {
+				return nil, nil, fmt.Errorf("fehler beim Löschen des Freiloses: %w", err)
+			}
+
+			entry, err := d.recordAudit(tx, d.actorOr(""), "match.delete", "match", match.ID, matchState(match), nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			entries = append(entries, entry)
+			removed = append(removed, match)
+			continue
+		}
+
+		// Der Gegner wird Heimteam, die Auswärtsseite bleibt leer (Free Win)
+		opponentID := match.TeamHomeID
+		if match.TeamHomeID == teamID {
+			opponentID = int(match.TeamAwayID.Int64)
+		}
+
+		_, err = tx.Exec(
+			"UPDATE matches SET team_home_id = ?, team_away_id = NULL, version = version + 1 WHERE id = ?",
+			opponentID, match.ID,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fehler beim Umwandeln in ein Freilos: %w", err)
+		}
+
+		entry, err := d.auditMatchChange(tx, "match.bye", match)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, entry)
+
+		after, err := getMatchByID(tx, match.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		changed = append(changed, after)
+	}
+
+	if err := d.commit(tx, entries...); err != nil {
+		return nil, nil, err
+	}
+
+	return changed, removed, nil
+}
+
+// AssignByeSlot setzt ein nachgemeldetes Team in den freien Platz eines Spielplans ein.
+// Ab fromMatchday wird in jedem Spieltag das einzige offene Freilos der Division mit
+// dem Team besetzt. Gibt es in einem Spieltag mehr als ein Freilos, ist die Zuordnung
+// nicht eindeutig und es wird nichts geändert.
+func (d *Database) AssignByeSlot(ctx context.Context, teamID, fromMatchday int) ([]*Match, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	team, err := getTeamByID(tx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, entries, err := d.assignByeSlot(tx, team, fromMatchday)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.commit(tx, entries...); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// LateEntry meldet ein Team nach: Existiert noch kein Team mit diesem Namen, wird es
+// angelegt, anschließend wird die Rolle gesetzt und das Team wie bei AssignByeSlot in
+// die freien Plätze ab fromMatchday eingesetzt. Alles geschieht in einer Transaktion,
+// schlägt das Einsetzen fehl, bleibt also auch kein neues Team ohne Matches zurück.
+func (d *Database) LateEntry(ctx context.Context, name string, division int, roleID string, fromMatchday int) (*Team, []*Match, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var entries []*AuditEntry
+
+	team, err := queryTeam(tx, ErrTeamNotFound, "WHERE name = ?", name)
+	switch {
+	case errors.Is(err, ErrTeamNotFound):
+		id, err := insertID(tx,
+			"INSERT INTO teams (name, division, role_id) VALUES (?, ?, ?)",
+			name, division, roleID,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fehler beim Erstellen des Teams: %w", err)
+		}
+		if team, err = getTeamByID(tx, id); err != nil {
+			return nil, nil, err
+		}
+		entry, err := d.recordAudit(tx, d.actorOr(""), "team.create", "team", team.ID, nil, teamState(team))
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, entry)
+	case err != nil:
+		return nil, nil, err
+	case team.Division != division:
+		return nil, nil, fmt.Errorf("team %s ist bereits in Division %d", team.Name, team.Division)
+	case team.RoleID != roleID:
+		if _, err := tx.Exec("UPDATE teams SET role_id = ? WHERE id = ?", roleID, team.ID); err != nil {
+			return nil, nil, fmt.Errorf("fehler beim Aktualisieren der Rollen-ID: %w", err)
+		}
+		entry, err := d.auditTeamChange(tx, "team.role", team)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, entry)
+		team.RoleID = roleID
+	}
+
+	changed, assigned, err := d.assignByeSlot(tx, team, fromMatchday)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries = append(entries, assigned...)
+
+	if err := d.commit(tx, entries...); err != nil {
+		return nil, nil, err
+	}
+
+	return team, changed, nil
+}
+
+// assignByeSlot besetzt innerhalb von tx die Freilose für AssignByeSlot und LateEntry
+func (d *Database) assignByeSlot(tx *txn, team *Team, fromMatchday int) ([]*Match, []*AuditEntry, error) {
+	matches, err := queryMatches(tx,
+		"WHERE division = ? AND matchday >= ? AND (score_home IS NULL OR score_away IS NULL) ORDER BY matchday, id",
+		team.Division, fromMatchday,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byes := make(map[int]*Match)
+	var matchdays []int
+	for _, match := range matches {
+		if match.TeamHomeID == team.ID || (match.TeamAwayID.Valid && int(match.TeamAwayID.Int64) == team.ID) {
+			return nil, nil, fmt.Errorf("team %s hat ab Spieltag %d bereits Matches", team.Name, fromMatchday)
+		}
+		if !match.IsBye() {
+			continue
+		}
+		if _, exists := byes[match.Matchday]; exists {
+			return nil, nil, fmt.Errorf("spieltag %d hat mehrere Freilose, der freie Platz ist nicht eindeutig", match.Matchday)
+		}
+		byes[match.Matchday] = match
+		matchdays = append(matchdays, match.Matchday)
+	}
+
+	if len(byes) == 0 {
+		return nil, nil, fmt.Errorf("division %d hat ab Spieltag %d keine freien Plätze", team.Division, fromMatchday)
+	}
+
+	var entries []*AuditEntry
+	var changed []*Match
+	for _, matchday := range matchdays {
+		match := byes[matchday]
+
+		if _, err := tx.Exec("UPDATE matches SET team_away_id = ?, version = version + 1 WHERE id = ?", team.ID, match.ID); err != nil {
+			return nil, nil, fmt.Errorf("fehler beim Besetzen des Freiloses: %w", err)
+		}
+
+		entry, err := d.auditMatchChange(tx, "match.assign_bye", match)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, entry)
+
+		after, err := getMatchByID(tx, match.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		changed = append(changed, after)
+	}
+
+	return changed, entries, nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// copyTables sind alle Tabellen in einer Reihenfolge, in der die Fremdschlüssel erfüllt bleiben
+var copyTables = []string{
+	"teams",
+	"players",
+	"matches",
+	"disqualified_matches",
+	"audit_log",
+	"webhook_deliveries",
+	"webhook_attempts",
+	"standings_messages",
+	"matchday_recaps",
+}
+
+// CopyTables gibt die Tabellen zurück, die CopyTo überträgt, in Kopierreihenfolge
+func CopyTables() []string {
+	return append([]string(nil), copyTables...)
+}
+
+// serialTables haben eine automatisch vergebene id, deren Sequenz nach dem Kopieren nachgezogen werden muss
+var serialTables = map[string]bool{
+	"teams":                true,
+	"players":              true,
+	"matches":              true,
+	"disqualified_matches": true,
+	"audit_log":            true,
+	"webhook_deliveries":   true,
+	"webhook_attempts":     true,
+}
+
+// CopyTo kopiert alle Tabellen in die leere Datenbank dst, z.B. von SQLite nach PostgreSQL.
+// IDs bleiben erhalten, damit Channel-Zuordnungen, Audit-Log und Webhooks weiter zusammenpassen.
+// Die Kopie läuft in einer Transaktion; bei einem Fehler bleibt dst unverändert.
+// Zurückgegeben wird die Anzahl der kopierten Zeilen pro Tabelle.
+func (d *Database) CopyTo(ctx context.Context, dst *Database) (map[string]int, error) {
+	tx, err := dst.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range copyTables {
+		var count int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			return nil, fmt.Errorf("fehler beim Prüfen der Zieltabelle %s: %w", table, err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("zieldatenbank ist nicht leer (%s enthält %d zeilen)", table, count)
+		}
+	}
+
+	copied := make(map[string]int, len(copyTables))
+	for _, table := range copyTables {
+		n, err := copyTable(d.with(ctx), tx, table)
+		if err != nil {
+			return nil, err
+		}
+		copied[table] = n
+
+		if n > 0 && serialTables[table] && dst.dialect == Postgres {
+			_, err := tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), MAX(id)) FROM %s", table, table))
+			if err != nil {
+				return nil, fmt.Errorf("fehler beim Setzen der ID-Sequenz von %s: %w", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("fehler beim Commit der Kopie: %w", err)
+	}
+	return copied, nil
+}
+
+// copyTable überträgt alle Zeilen einer Tabelle mit ihren Spaltennamen aus der Quelle
+func copyTable(src querier, dst querier, table string) (int, error) {
+	rows, err := src.Query("SELECT * FROM " + table)
+	if err != nil {
+		return 0, fmt.Errorf("fehler beim Lesen von %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("fehler beim Lesen der Spalten von %s: %w", table, err)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for n := range values {
+		ptrs[n] = &values[n]
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, fmt.Errorf("fehler beim Lesen von %s: %w", table, err)
+		}
+		for n, v := range values {
+			// Texte liefert SQLite teilweise als []byte, PostgreSQL würde sie als bytea senden
+			if b, ok := v.([]byte); ok {
+				values[n] = string(b)
+			}
+		}
+		if _, err := dst.Exec(insert, values...); err != nil {
+			return 0, fmt.Errorf("fehler beim Kopieren von %s (Zeile %d): %w", table, count+1, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("fehler beim Lesen von %s: %w", table, err)
+	}
+
+	return count, nil
+}
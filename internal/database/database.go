@@ -1,34 +1,66 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"fmt"
-
-	_ "github.com/mattn/go-sqlite3"
+	"log"
+	"time"
 )
 
 //go:embed schema.sql
 var schema string
 
+//go:embed schema_postgres.sql
+var postgresSchema string
+
 type Database struct {
 	DB *sql.DB
+
+	// writer ist der Pool für Transaktionen und Änderungen. Bei SQLite hat er genau eine
+	// Verbindung, damit immer nur ein Schreiber gleichzeitig aktiv ist; bei PostgreSQL ist er DB.
+	writer  *sql.DB
+	dialect Dialect
+
+	// actor ist die Discord User-ID, der Änderungen im Audit-Log zugeordnet werden,
+	// reason eine optionale Begründung dazu
+	actor     string
+	reason    string
+	auditHook func(*AuditEntry)
+
+	// webhooks reiht die Events aus WithEvent ein, events sind die Events dieser Sicht
+	webhooks WebhookRouter
+	events   []pendingEvent
 }
 
-// New erstellt eine neue Datenbankverbindung und initialisiert das Schema
-func New(dbPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+// New erstellt eine neue Datenbankverbindung und initialisiert das Schema.
+// dsn ist ein SQLite-Pfad (z.B. data/league.db) oder eine PostgreSQL-URL (postgres://...).
+func New(dsn string) (*Database, error) {
+	dialect := dialectFor(dsn)
+
+	var (
+		db, writer *sql.DB
+		err        error
+	)
+	if dialect == SQLite {
+		db, writer, err = openSQLite(dsn)
+	} else {
+		db, err = sql.Open(dialect.driver(), dsn)
+		writer = db
+	}
 	if err != nil {
 		return nil, fmt.Errorf("fehler beim Öffnen der Datenbank: %w", err)
 	}
 
+	database := &Database{DB: db, writer: writer, dialect: dialect}
+
 	// Verbindung testen
-	if err := db.Ping(); err != nil {
+	if err := writer.Ping(); err != nil {
+		database.Close()
 		return nil, fmt.Errorf("fehler beim Verbinden zur Datenbank: %w", err)
 	}
 
-	database := &Database{DB: db}
-
 	// Schema initialisieren
 	if err := database.initSchema(); err != nil {
 		return nil, fmt.Errorf("fehler beim Initialisieren des Schemas: %w", err)
@@ -37,16 +69,214 @@ func New(dbPath string) (*Database, error) {
 	return database, nil
 }
 
+// Dialect gibt den SQL-Dialekt der Datenbank zurück
+func (d *Database) Dialect() Dialect {
+	return d.dialect
+}
+
+// addedColumns sind Spalten, die nach der ersten Version des Schemas hinzugekommen sind.
+// Sie werden bei bestehenden Datenbanken per ALTER TABLE ergänzt. postgres ist die Definition
+// für PostgreSQL, dort sind alle bisherigen Spalten bereits in schema_postgres.sql enthalten.
+var addedColumns = []struct {
+	table      string
+	column     string
+	definition string
+	postgres   string
+}{
+	{"audit_log", "reason", "TEXT", "TEXT"},
+	{"teams", "is_withdrawn", "BOOLEAN DEFAULT 0", "BOOLEAN DEFAULT FALSE"},
+	{"teams", "withdrawn_at", "DATETIME", "TIMESTAMPTZ"},
+	{"matches", "scheduled_at", "DATETIME", "TIMESTAMPTZ"},
+	{"matches", "version", "INTEGER NOT NULL DEFAULT 0", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// dataMigrations bringen Zeilen aus älteren Versionen in die aktuelle Form. Sie laufen bei jedem
+// Start nach addedColumns und ändern nur Zeilen, die noch nicht migriert sind.
+var dataMigrations = []struct {
+	name  string
+	query string
+}{
+	// Freilose aus dem 9er-Spielplan hatten team_home_id = 0 und das Team auswärts. Jetzt spielt das
+	// Team wie bei Free Wins zu Hause und die Auswärtsseite bleibt leer. Mit den Seiten werden auch
+	// die Ergebnisse getauscht, sonst hätte ein disqualifiziertes Team sein Freilos 3:0 gewonnen.
+	// Gesicherte Zustände vor Disqualifikationen zuerst, solange die Freilose noch erkennbar sind.
+	{"freilose_disqualifikationen", `UPDATE disqualified_matches
+		SET prev_score_home = prev_score_away, prev_score_away = prev_score_home
+		WHERE match_id IN (SELECT id FROM matches WHERE team_home_id = 0 AND team_away_id IS NOT NULL)`},
+	{"freilose", `UPDATE matches
+		SET team_home_id = team_away_id, team_away_id = NULL,
+		    score_home = score_away, score_away = score_home, version = version + 1
+		WHERE team_home_id = 0 AND team_away_id IS NOT NULL`},
+}
+
+// postgresHomeTeamForeignKey ergänzt den Fremdschlüssel auf team_home_id in PostgreSQL-Datenbanken,
+// die ohne ihn angelegt wurden. Er kann erst nach der Migration der Freilose gesetzt werden.
+const postgresHomeTeamForeignKey = `
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = 'matches'::regclass AND conname = 'matches_team_home_id_fkey') THEN
+        ALTER TABLE matches ADD CONSTRAINT matches_team_home_id_fkey FOREIGN KEY (team_home_id) REFERENCES teams(id);
+    END IF;
+END
+$$`
+
+// schemaLock ist der Schlüssel der Advisory-Lock, mit der PostgreSQL das Schema nur von
+// einem Prozess gleichzeitig anlegen lässt (Bot und API starten oft zeitgleich), "PLS4"
+const schemaLock = 0x504c5334
+
 // initSchema führt das SQL Schema aus
 func (d *Database) initSchema() error {
-	_, err := d.DB.Exec(schema)
+	if d.dialect == Postgres {
+		return d.initPostgresSchema()
+	}
+
+	_, err := d.writer.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("fehler beim Ausführen des Schemas: %w", err)
 	}
+
+	for _, c := range addedColumns {
+		if err := d.ensureColumn(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range dataMigrations {
+		if _, err := d.writer.Exec(m.query); err != nil {
+			return fmt.Errorf("fehler bei der Migration %s: %w", m.name, err)
+		}
+	}
+
+	violations, err := d.checkForeignKeys(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, v := range violations {
+		log.Printf("[Database] Fremdschlüssel verletzt: %s", v)
+	}
 	return nil
 }
 
-// Close schließt die Datenbankverbindung
+// initPostgresSchema führt das PostgreSQL-Schema in einer Transaktion unter einer Advisory-Lock aus
+func (d *Database) initPostgresSchema() error {
+	tx, err := d.begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", schemaLock); err != nil {
+		return fmt.Errorf("fehler beim Sperren des Schemas: %w", err)
+	}
+	if _, err := tx.Exec(postgresSchema); err != nil {
+		return fmt.Errorf("fehler beim Ausführen des Schemas: %w", err)
+	}
+
+	for _, c := range addedColumns {
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", c.table, c.column, c.postgres)
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("fehler beim Hinzufügen der Spalte %s.%s: %w", c.table, c.column, err)
+		}
+	}
+
+	for _, m := range dataMigrations {
+		if _, err := tx.Exec(m.query); err != nil {
+			return fmt.Errorf("fehler bei der Migration %s: %w", m.name, err)
+		}
+	}
+	if _, err := tx.Exec(postgresHomeTeamForeignKey); err != nil {
+		return fmt.Errorf("fehler beim Hinzufügen des Fremdschlüssels auf team_home_id: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("fehler beim Commit des Schemas: %w", err)
+	}
+	return nil
+}
+
+// ensureColumn fügt eine Spalte hinzu, falls sie in der SQLite-Tabelle noch nicht existiert
+func (d *Database) ensureColumn(table, column, definition string) error {
+	rows, err := d.writer.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("fehler beim Lesen der Tabellenstruktur von %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("fehler beim Lesen der Tabellenstruktur von %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	if _, err := d.writer.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("fehler beim Hinzufügen der Spalte %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// commit reiht die Events der Sicht ein, schließt die Transaktion ab und meldet die Audit-Einträge an den Hook
+func (d *Database) commit(tx *txn, entries ...*AuditEntry) error {
+	queued, err := d.enqueueEvents(tx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("fehler beim Commit der Transaktion: %w", err)
+	}
+	d.publishAudit(entries...)
+	if queued {
+		d.webhooks.Notify()
+	}
+	return nil
+}
+
+// LastModified gibt den Zeitpunkt der letzten Änderung an Teams oder Matches zurück
+func (d *Database) LastModified() (time.Time, error) {
+	var value sql.NullString
+	err := d.DB.QueryRow(`
+		SELECT MAX(ts) FROM (
+			SELECT MAX(created_at) AS ts FROM audit_log
+			UNION ALL SELECT MAX(updated_at) FROM teams
+			UNION ALL SELECT MAX(created_at) FROM matches
+			UNION ALL SELECT MAX(reported_at) FROM matches
+		) AS changes
+	`).Scan(&value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fehler beim Abrufen der letzten Änderung: %w", err)
+	}
+
+	if !value.Valid {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00"} {
+		if t, err := time.Parse(layout, value.String); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unbekanntes Zeitformat: %s", value.String)
+}
+
+// Close schließt die Datenbankverbindungen
 func (d *Database) Close() error {
-	return d.DB.Close()
+	err := d.DB.Close()
+	if d.writer != d.DB {
+		if werr := d.writer.Close(); err == nil {
+			err = werr
+		}
+	}
+	return err
 }
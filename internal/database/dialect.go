@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect ist der SQL-Dialekt der Datenbank
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite3"
+	Postgres Dialect = "postgres"
+)
+
+// sqliteTimeLayout ist das Format, in dem SQLite Zeitpunkte für SQL-Vergleiche speichert
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// dialectFor erkennt den Dialekt am DSN: postgres:// und postgresql:// sind PostgreSQL, alles andere ist ein SQLite-Pfad
+func dialectFor(dsn string) Dialect {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		return Postgres
+	}
+	return SQLite
+}
+
+// driver gibt den Namen des database/sql Treibers zurück
+func (dl Dialect) driver() string {
+	return string(dl)
+}
+
+// rebind ersetzt die ?-Platzhalter durch $1, $2, ... für PostgreSQL.
+// Fragezeichen in String-Literalen bleiben erhalten.
+func (dl Dialect) rebind(query string) string {
+	if dl != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// timeArg bereitet einen Zeitpunkt für Vergleiche mit Zeitspalten vor.
+// SQLite vergleicht Zeitpunkte als Text im Format von CURRENT_TIMESTAMP.
+func (dl Dialect) timeArg(t time.Time) any {
+	if dl == Postgres {
+		return t.UTC()
+	}
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// IsDuplicate prüft, ob ein Fehler von einer verletzten UNIQUE-Bedingung stammt (z.B. doppelter Teamname)
+func IsDuplicate(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" // unique_violation
+	}
+	return false
+}
@@ -0,0 +1,454 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExportVersion ist die Version des JSON-Formats von LeagueExport. Sie wird erhöht, sobald sich
+// das Format inkompatibel ändert; Import lehnt Exporte mit unbekannter Version ab.
+const ExportVersion = 1
+
+// LeagueExport ist der komplette Zustand einer Liga für migrate export und migrate import.
+// Alle IDs sind die der exportierenden Datenbank und dienen nur als Verweise innerhalb des Exports,
+// beim Import werden neue IDs vergeben.
+type LeagueExport struct {
+	Version           int                        `json:"version"`
+	ExportedAt        time.Time                  `json:"exported_at"`
+	Teams             []ExportedTeam             `json:"teams"`
+	Matches           []ExportedMatch            `json:"matches"`
+	Disqualifications []ExportedDisqualification `json:"disqualifications,omitempty"`
+	Channels          ExportedChannels           `json:"channels"`
+}
+
+// ExportedTeam ist ein Team mit Roster
+type ExportedTeam struct {
+	ID             int              `json:"id"`
+	Name           string           `json:"name"`
+	Division       int              `json:"division"`
+	RoleID         string           `json:"role_id,omitempty"`
+	IsDisqualified bool             `json:"is_disqualified,omitempty"`
+	DisqualifiedAt *time.Time       `json:"disqualified_at,omitempty"`
+	IsWithdrawn    bool             `json:"is_withdrawn,omitempty"`
+	WithdrawnAt    *time.Time       `json:"withdrawn_at,omitempty"`
+	Players        []ExportedPlayer `json:"players,omitempty"`
+}
+
+// ExportedPlayer ist ein Spieler im Roster, in Roster-Reihenfolge
+type ExportedPlayer struct {
+	Name       string `json:"name"`
+	TrackerURL string `json:"tracker_url,omitempty"`
+}
+
+// ExportedMatch ist ein Match mit Ergebnis und Channel
+type ExportedMatch struct {
+	ID       int `json:"id"`
+	Division int `json:"division"`
+	Matchday int `json:"matchday"`
+	// TeamAwayID ist nil bei Freilosen
+	TeamHomeID  int             `json:"team_home_id"`
+	TeamAwayID  *int            `json:"team_away_id"`
+	Result      *ExportedResult `json:"result,omitempty"`
+	ChannelID   string          `json:"channel_id,omitempty"`
+	ScheduledAt *time.Time      `json:"scheduled_at,omitempty"`
+}
+
+// ExportedResult ist ein eingetragenes Ergebnis
+type ExportedResult struct {
+	ScoreHome  int        `json:"score_home"`
+	ScoreAway  int        `json:"score_away"`
+	ReportedAt *time.Time `json:"reported_at,omitempty"`
+	ReportedBy string     `json:"reported_by,omitempty"`
+}
+
+// ExportedDisqualification ist der Zustand eines Matches vor der Disqualifikation eines Teams (für /requalify)
+type ExportedDisqualification struct {
+	TeamID  int             `json:"team_id"`
+	MatchID int             `json:"match_id"`
+	Result  *ExportedResult `json:"result,omitempty"`
+}
+
+// ExportedChannels sind die Nachrichten, die der Bot in Discord pflegt
+type ExportedChannels struct {
+	Standings []ExportedMessage `json:"standings,omitempty"`
+	Recaps    []ExportedMessage `json:"recaps,omitempty"`
+}
+
+// ExportedMessage ist eine Tabellen-Nachricht (ohne Matchday) oder ein Spieltag-Rückblick
+type ExportedMessage struct {
+	Division  int    `json:"division"`
+	Matchday  int    `json:"matchday,omitempty"`
+	ChannelID string `json:"channel_id"`
+	MessageID string `json:"message_id"`
+}
+
+// ImportOptions steuern migrate import
+type ImportOptions struct {
+	// WithoutChannels lässt Match-Channels, Tabellen-Nachrichten und Rückblicke weg,
+	// z.B. wenn Staging einen anderen Discord-Server verwendet
+	WithoutChannels bool
+}
+
+// Export liest den kompletten Liga-Zustand. Der Export läuft in einer Transaktion,
+// damit Teams, Matches und Ergebnisse zueinander passen.
+func (d *Database) Export(ctx context.Context) (*LeagueExport, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	exp := &LeagueExport{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Teams:      []ExportedTeam{},
+		Matches:    []ExportedMatch{},
+	}
+
+	teams, err := queryTeams(tx, "ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	players, err := queryAll(tx, scanPlayer, "SELECT id, team_id, name, tracker_url, position, created_at FROM players ORDER BY team_id, position, id")
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Spieler: %w", err)
+	}
+	roster := make(map[int][]ExportedPlayer)
+	for _, p := range players {
+		roster[p.TeamID] = append(roster[p.TeamID], ExportedPlayer{Name: p.Name, TrackerURL: p.TrackerURL})
+	}
+	for _, t := range teams {
+		exp.Teams = append(exp.Teams, ExportedTeam{
+			ID:             t.ID,
+			Name:           t.Name,
+			Division:       t.Division,
+			RoleID:         t.RoleID,
+			IsDisqualified: t.IsDisqualified,
+			DisqualifiedAt: timePtr(t.DisqualifiedAt),
+			IsWithdrawn:    t.IsWithdrawn,
+			WithdrawnAt:    timePtr(t.WithdrawnAt),
+			Players:        roster[t.ID],
+		})
+	}
+
+	matches, err := queryMatches(tx, "ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		em := ExportedMatch{
+			ID:          m.ID,
+			Division:    m.Division,
+			Matchday:    m.Matchday,
+			TeamHomeID:  m.TeamHomeID,
+			Result:      exportResult(m.ScoreHome, m.ScoreAway, m.ReportedAt, m.ReportedBy),
+			ChannelID:   m.ChannelID.String,
+			ScheduledAt: timePtr(m.ScheduledAt),
+		}
+		if m.TeamAwayID.Valid {
+			away := int(m.TeamAwayID.Int64)
+			em.TeamAwayID = &away
+		}
+		exp.Matches = append(exp.Matches, em)
+	}
+
+	disqualified, err := queryAll(tx, scanDisqualifiedMatch,
+		`SELECT team_id, match_id, prev_score_home, prev_score_away, prev_reported_at, prev_reported_by, created_at
+		 FROM disqualified_matches ORDER BY team_id, match_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der gesicherten Match-Zustände: %w", err)
+	}
+	for _, dm := range disqualified {
+		exp.Disqualifications = append(exp.Disqualifications, ExportedDisqualification{
+			TeamID:  dm.TeamID,
+			MatchID: dm.MatchID,
+			Result:  exportResult(dm.PrevScoreHome, dm.PrevScoreAway, dm.PrevReportedAt, dm.PrevReportedBy),
+		})
+	}
+
+	exp.Channels.Standings, err = queryAll(tx, scanExportedMessage,
+		"SELECT division, 0, channel_id, message_id FROM standings_messages ORDER BY division")
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Tabellen-Nachrichten: %w", err)
+	}
+	exp.Channels.Recaps, err = queryAll(tx, scanExportedMessage,
+		"SELECT division, matchday, channel_id, message_id FROM matchday_recaps ORDER BY division, matchday")
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Spieltag-Rückblicke: %w", err)
+	}
+
+	return exp, nil
+}
+
+func scanExportedMessage(row rowScanner) (ExportedMessage, error) {
+	var msg ExportedMessage
+	err := row.Scan(&msg.Division, &msg.Matchday, &msg.ChannelID, &msg.MessageID)
+	return msg, err
+}
+
+// exportResult gibt das Ergebnis zurück oder nil, wenn noch keins eingetragen ist
+func exportResult(scoreHome, scoreAway sql.NullInt64, reportedAt sql.NullTime, reportedBy sql.NullString) *ExportedResult {
+	if !scoreHome.Valid || !scoreAway.Valid {
+		return nil
+	}
+	return &ExportedResult{
+		ScoreHome:  int(scoreHome.Int64),
+		ScoreAway:  int(scoreAway.Int64),
+		ReportedAt: timePtr(reportedAt),
+		ReportedBy: reportedBy.String,
+	}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// timeValue gibt einen optionalen Zeitpunkt als Abfrage-Parameter zurück
+func timeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// Validate prüft einen Export auf Version und referenzielle Integrität: eindeutige IDs und
+// Teamnamen, Verweise auf existierende Teams und Matches sowie gültige Ergebnisse.
+// Alle gefundenen Fehler werden gemeinsam zurückgegeben.
+func (exp *LeagueExport) Validate() error {
+	if exp.Version < 1 || exp.Version > ExportVersion {
+		return fmt.Errorf("exportversion %d wird nicht unterstützt (unterstützt: 1 bis %d)", exp.Version, ExportVersion)
+	}
+
+	var errs []error
+	teams := make(map[int]bool, len(exp.Teams))
+	names := make(map[string]bool, len(exp.Teams))
+	for _, t := range exp.Teams {
+		switch {
+		case t.ID <= 0:
+			errs = append(errs, fmt.Errorf("team %q hat ungültige id %d", t.Name, t.ID))
+		case teams[t.ID]:
+			errs = append(errs, fmt.Errorf("team-id %d ist doppelt vergeben", t.ID))
+		}
+		teams[t.ID] = true
+
+		if t.Name == "" {
+			errs = append(errs, fmt.Errorf("team %d hat keinen namen", t.ID))
+		} else if names[t.Name] {
+			errs = append(errs, fmt.Errorf("teamname %q ist doppelt vergeben", t.Name))
+		}
+		names[t.Name] = true
+
+		if t.Division <= 0 {
+			errs = append(errs, fmt.Errorf("team %q hat ungültige division %d", t.Name, t.Division))
+		}
+	}
+
+	matches := make(map[int]bool, len(exp.Matches))
+	for _, m := range exp.Matches {
+		if m.ID <= 0 || matches[m.ID] {
+			errs = append(errs, fmt.Errorf("match-id %d ist ungültig oder doppelt vergeben", m.ID))
+		}
+		matches[m.ID] = true
+
+		if m.Division <= 0 || m.Matchday <= 0 {
+			errs = append(errs, fmt.Errorf("match %d hat ungültige division %d oder spieltag %d", m.ID, m.Division, m.Matchday))
+		}
+		if !teams[m.TeamHomeID] {
+			errs = append(errs, fmt.Errorf("match %d verweist auf unbekanntes heimteam %d", m.ID, m.TeamHomeID))
+		}
+		if m.TeamAwayID != nil && !teams[*m.TeamAwayID] {
+			errs = append(errs, fmt.Errorf("match %d verweist auf unbekanntes auswärtsteam %d", m.ID, *m.TeamAwayID))
+		}
+		if m.TeamAwayID != nil && *m.TeamAwayID == m.TeamHomeID {
+			errs = append(errs, fmt.Errorf("match %d: team %d spielt gegen sich selbst", m.ID, m.TeamHomeID))
+		}
+		if err := validateExportedResult(m.Result); err != nil {
+			errs = append(errs, fmt.Errorf("match %d: %w", m.ID, err))
+		}
+	}
+
+	for _, dq := range exp.Disqualifications {
+		if !teams[dq.TeamID] || !matches[dq.MatchID] {
+			errs = append(errs, fmt.Errorf("disqualifikation verweist auf unbekanntes team %d oder match %d", dq.TeamID, dq.MatchID))
+		}
+		if err := validateExportedResult(dq.Result); err != nil {
+			errs = append(errs, fmt.Errorf("disqualifikation von team %d in match %d: %w", dq.TeamID, dq.MatchID, err))
+		}
+	}
+
+	for _, msg := range append(append([]ExportedMessage(nil), exp.Channels.Standings...), exp.Channels.Recaps...) {
+		if msg.Division <= 0 || msg.ChannelID == "" || msg.MessageID == "" {
+			errs = append(errs, fmt.Errorf("nachricht in division %d ist unvollständig", msg.Division))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateExportedResult(r *ExportedResult) error {
+	if r == nil {
+		return nil
+	}
+	if r.ScoreHome < 0 || r.ScoreHome > 4 || r.ScoreAway < 0 || r.ScoreAway > 4 {
+		return fmt.Errorf("ergebnis %d:%d liegt nicht zwischen 0 und 4", r.ScoreHome, r.ScoreAway)
+	}
+	return nil
+}
+
+// Import spielt einen Export in die leere Datenbank ein. Teams und Matches bekommen neue IDs,
+// alle Verweise im Export werden darauf umgeschrieben. Der Import läuft in einer Transaktion;
+// schlägt eine Prüfung fehl, bleibt die Datenbank unverändert.
+// Zurückgegeben wird die Anzahl der importierten Einträge pro Art.
+func (d *Database) Import(ctx context.Context, exp *LeagueExport, opts ImportOptions) (map[string]int, error) {
+	if err := exp.Validate(); err != nil {
+		return nil, fmt.Errorf("export ist ungültig:\n%w", err)
+	}
+
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"teams", "matches"} {
+		var count int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			return nil, fmt.Errorf("fehler beim Prüfen der Zieltabelle %s: %w", table, err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("zieldatenbank ist nicht leer (%s enthält %d zeilen)", table, count)
+		}
+	}
+
+	imported := make(map[string]int)
+
+	// Alte IDs aus dem Export auf die neu vergebenen abbilden
+	teamIDs := make(map[int]int, len(exp.Teams))
+	for _, t := range exp.Teams {
+		var roleID any
+		if t.RoleID != "" {
+			roleID = t.RoleID
+		}
+		id, err := insertID(tx,
+			`INSERT INTO teams (name, division, role_id, is_disqualified, disqualified_at, is_withdrawn, withdrawn_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			t.Name, t.Division, roleID, t.IsDisqualified, timeValue(t.DisqualifiedAt), t.IsWithdrawn, timeValue(t.WithdrawnAt),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Importieren von Team %q: %w", t.Name, err)
+		}
+		teamIDs[t.ID] = id
+		imported["teams"]++
+
+		for position, p := range t.Players {
+			_, err := tx.Exec(
+				"INSERT INTO players (team_id, name, tracker_url, position) VALUES (?, ?, ?, ?)",
+				id, p.Name, p.TrackerURL, position,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("fehler beim Importieren des Rosters von Team %q: %w", t.Name, err)
+			}
+			imported["players"]++
+		}
+	}
+
+	matchIDs := make(map[int]int, len(exp.Matches))
+	for _, m := range exp.Matches {
+		var awayID any
+		if m.TeamAwayID != nil {
+			awayID = teamIDs[*m.TeamAwayID]
+		}
+		var channelID any
+		if m.ChannelID != "" && !opts.WithoutChannels {
+			channelID = m.ChannelID
+		}
+		scoreHome, scoreAway, reportedAt, reportedBy := resultValues(m.Result)
+
+		id, err := insertID(tx,
+			`INSERT INTO matches (division, matchday, team_home_id, team_away_id, score_home, score_away,
+			                      reported_at, reported_by, channel_id, scheduled_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			m.Division, m.Matchday, teamIDs[m.TeamHomeID], awayID, scoreHome, scoreAway,
+			reportedAt, reportedBy, channelID, timeValue(m.ScheduledAt),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Importieren von Match %d: %w", m.ID, err)
+		}
+		matchIDs[m.ID] = id
+		imported["matches"]++
+	}
+
+	for _, dq := range exp.Disqualifications {
+		scoreHome, scoreAway, reportedAt, reportedBy := resultValues(dq.Result)
+		_, err := tx.Exec(
+			`INSERT INTO disqualified_matches (team_id, match_id, prev_score_home, prev_score_away, prev_reported_at, prev_reported_by)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			teamIDs[dq.TeamID], matchIDs[dq.MatchID], scoreHome, scoreAway, reportedAt, reportedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("fehler beim Importieren der Disqualifikation von Team %d: %w", dq.TeamID, err)
+		}
+		imported["disqualifications"]++
+	}
+
+	if !opts.WithoutChannels {
+		for _, msg := range exp.Channels.Standings {
+			_, err := tx.Exec(
+				`INSERT INTO standings_messages (division, channel_id, message_id, updated_at)
+				 VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+				 ON CONFLICT(division) DO UPDATE SET
+				     channel_id = excluded.channel_id,
+				     message_id = excluded.message_id,
+				     updated_at = CURRENT_TIMESTAMP`,
+				msg.Division, msg.ChannelID, msg.MessageID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("fehler beim Importieren der Tabellen-Nachricht: %w", err)
+			}
+			imported["standings"]++
+		}
+		for _, msg := range exp.Channels.Recaps {
+			_, err := tx.Exec(
+				`INSERT INTO matchday_recaps (division, matchday, channel_id, message_id)
+				 VALUES (?, ?, ?, ?)
+				 ON CONFLICT(division, matchday) DO UPDATE SET
+				     channel_id = excluded.channel_id,
+				     message_id = excluded.message_id`,
+				msg.Division, msg.Matchday, msg.ChannelID, msg.MessageID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("fehler beim Importieren des Spieltag-Rückblicks: %w", err)
+			}
+			imported["recaps"]++
+		}
+	}
+
+	entry, err := d.recordAudit(tx, d.actorOr(""), "league.import", "league", 0, nil, imported)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.commit(tx, entry); err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
+
+// resultValues gibt die Spaltenwerte eines optionalen Ergebnisses zurück (alle NULL ohne Ergebnis)
+func resultValues(r *ExportedResult) (scoreHome, scoreAway, reportedAt, reportedBy any) {
+	if r == nil {
+		return nil, nil, nil, nil
+	}
+	if r.ReportedBy != "" {
+		reportedBy = r.ReportedBy
+	}
+	return r.ScoreHome, r.ScoreAway, timeValue(r.ReportedAt), reportedBy
+}
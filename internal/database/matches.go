@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -18,17 +20,69 @@ type Match struct {
 	ChannelID  sql.NullString
 	ReportedAt sql.NullTime
 	ReportedBy sql.NullString
-	CreatedAt  time.Time
+	// ScheduledAt ist der zwischen den Teams vereinbarte Spieltermin (/match_time)
+	ScheduledAt sql.NullTime
+	// Version wird bei jeder Änderung des Ergebnisses erhöht, siehe UpdateMatchScore
+	Version   int
+	CreatedAt time.Time
+}
+
+// ResultConflictError meldet, dass sich das Ergebnis eines Matches geändert hat,
+// seit der Melder es gelesen hat. Current ist der aktuelle Stand des Matches.
+type ResultConflictError struct {
+	Current *Match
+}
+
+func (e *ResultConflictError) Error() string {
+	return fmt.Sprintf("das ergebnis von match %d wurde inzwischen geändert", e.Current.ID)
+}
+
+// matchColumns sind die Spalten, die scanMatch erwartet
+const matchColumns = `id, division, matchday, team_home_id, team_away_id, 
+		 score_home, score_away, channel_id, reported_at, reported_by, scheduled_at, version, created_at`
+
+func scanMatch(row rowScanner) (*Match, error) {
+	match := &Match{}
+	err := row.Scan(
+		&match.ID, &match.Division, &match.Matchday, &match.TeamHomeID, &match.TeamAwayID,
+		&match.ScoreHome, &match.ScoreAway, &match.ChannelID, &match.ReportedAt,
+		&match.ReportedBy, &match.ScheduledAt, &match.Version, &match.CreatedAt,
+	)
+	return match, err
+}
+
+// queryMatches ruft alle Matches ab, die auf die angegebene WHERE/ORDER-Klausel passen
+func queryMatches(q querier, clause string, args ...any) ([]*Match, error) {
+	matches, err := queryAll(q, scanMatch, "SELECT "+matchColumns+" FROM matches "+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Matches: %w", err)
+	}
+	return matches, nil
+}
+
+// queryMatch ruft das Match ab, das auf die WHERE-Klausel passt. Gibt es keins, wird notFound zurückgegeben.
+func queryMatch(q querier, notFound error, clause string, args ...any) (*Match, error) {
+	match, err := queryOne(q, scanMatch, notFound, "SELECT "+matchColumns+" FROM matches "+clause, args...)
+	if err != nil && err != notFound {
+		return nil, fmt.Errorf("fehler beim Abrufen des Matches: %w", err)
+	}
+	return match, err
 }
 
 // CreateMatch erstellt ein neues Match
-func (d *Database) CreateMatch(division, matchday, teamHomeID int, teamAwayID *int) (*Match, error) {
+func (d *Database) CreateMatch(ctx context.Context, division, matchday, teamHomeID int, teamAwayID *int) (*Match, error) {
 	var awayID sql.NullInt64
 	if teamAwayID != nil {
 		awayID = sql.NullInt64{Int64: int64(*teamAwayID), Valid: true}
 	}
 
-	result, err := d.DB.Exec(
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertID(tx,
 		"INSERT INTO matches (division, matchday, team_home_id, team_away_id) VALUES (?, ?, ?, ?)",
 		division, matchday, teamHomeID, awayID,
 	)
@@ -36,175 +90,290 @@ func (d *Database) CreateMatch(division, matchday, teamHomeID int, teamAwayID *i
 		return nil, fmt.Errorf("fehler beim Erstellen des Matches: %w", err)
 	}
 
-	id, err := result.LastInsertId()
+	match, err := getMatchByID(tx, id)
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abrufen der Match-ID: %w", err)
+		return nil, err
 	}
 
-	return d.GetMatchByID(int(id))
-}
-
-// GetMatchByID ruft ein Match anhand der ID ab
-func (d *Database) GetMatchByID(id int) (*Match, error) {
-	match := &Match{}
-	err := d.DB.QueryRow(
-		`SELECT id, division, matchday, team_home_id, team_away_id, 
-		 score_home, score_away, channel_id, reported_at, reported_by, created_at 
-		 FROM matches WHERE id = ?`,
-		id,
-	).Scan(
-		&match.ID, &match.Division, &match.Matchday, &match.TeamHomeID, &match.TeamAwayID,
-		&match.ScoreHome, &match.ScoreAway, &match.ChannelID, &match.ReportedAt,
-		&match.ReportedBy, &match.CreatedAt,
-	)
-
+	entry, err := d.recordAudit(tx, d.actorOr(""), "match.create", "match", match.ID, nil, matchState(match))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("match mit ID %d nicht gefunden", id)
-		}
-		return nil, fmt.Errorf("fehler beim Abrufen des Matches: %w", err)
+		return nil, err
+	}
+
+	if err := d.commit(tx, entry); err != nil {
+		return nil, err
 	}
 
 	return match, nil
 }
 
+// GetMatchByID ruft ein Match anhand der ID ab
+func (d *Database) GetMatchByID(ctx context.Context, id int) (*Match, error) {
+	return getMatchByID(d.with(ctx), id)
+}
+
+func getMatchByID(q querier, id int) (*Match, error) {
+	return queryMatch(q, fmt.Errorf("match mit ID %d nicht gefunden", id), "WHERE id = ?", id)
+}
+
 // GetMatchesByDivision ruft alle Matches einer Division ab
-func (d *Database) GetMatchesByDivision(division int) ([]*Match, error) {
-	rows, err := d.DB.Query(
-		`SELECT id, division, matchday, team_home_id, team_away_id, 
-		 score_home, score_away, channel_id, reported_at, reported_by, created_at 
-		 FROM matches WHERE division = ? ORDER BY matchday, id`,
-		division,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abrufen der Matches: %w", err)
+func (d *Database) GetMatchesByDivision(ctx context.Context, division int) ([]*Match, error) {
+	return queryMatches(d.with(ctx), "WHERE division = ? ORDER BY matchday, id", division)
+}
+
+// GetMatchesByDivisionAndMatchday ruft alle Matches eines Spieltags ab
+func (d *Database) GetMatchesByDivisionAndMatchday(ctx context.Context, division, matchday int) ([]*Match, error) {
+	return queryMatches(d.with(ctx), "WHERE division = ? AND matchday = ? ORDER BY id", division, matchday)
+}
+
+// GetAllMatches ruft alle Matches aller Divisionen ab
+func (d *Database) GetAllMatches(ctx context.Context) ([]*Match, error) {
+	return queryMatches(d.with(ctx), "ORDER BY division, matchday, id")
+}
+
+// GetMatchesByTeam ruft alle Matches eines Teams ab
+func (d *Database) GetMatchesByTeam(ctx context.Context, teamID int) ([]*Match, error) {
+	return queryMatches(d.with(ctx), "WHERE team_home_id = ? OR team_away_id = ? ORDER BY matchday, id", teamID, teamID)
+}
+
+// ErrByeResult wird zurückgegeben, wenn für ein Freilos ein Ergebnis eingetragen werden soll
+var ErrByeResult = errors.New("für ein freilos kann kein ergebnis eingetragen werden")
+
+// UpdateMatchScore trägt das Ergebnis eines Matches ein, sofern es noch die Version hat,
+// die der Melder gelesen hat. Hat jemand anderes das Ergebnis inzwischen eingetragen oder
+// geändert, wird nichts gespeichert und ein *ResultConflictError zurückgegeben.
+// Freilose haben keinen Gegner und bekommen nie ein Ergebnis (ErrByeResult).
+func (d *Database) UpdateMatchScore(ctx context.Context, id, version, scoreHome, scoreAway int, reportedBy string) error {
+	if scoreHome < 0 || scoreHome > 4 || scoreAway < 0 || scoreAway > 4 {
+		return fmt.Errorf("scores müssen zwischen 0 und 4 liegen")
 	}
-	defer rows.Close()
-
-	var matches []*Match
-	for rows.Next() {
-		match := &Match{}
-		if err := rows.Scan(
-			&match.ID, &match.Division, &match.Matchday, &match.TeamHomeID, &match.TeamAwayID,
-			&match.ScoreHome, &match.ScoreAway, &match.ChannelID, &match.ReportedAt,
-			&match.ReportedBy, &match.CreatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("fehler beim Scannen des Matches: %w", err)
-		}
-		matches = append(matches, match)
+
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	return matches, nil
-}
+	before, err := getMatchByID(tx, id)
+	if err != nil {
+		return err
+	}
+	if before.IsBye() {
+		return ErrByeResult
+	}
+	if before.Version != version {
+		return &ResultConflictError{Current: before}
+	}
 
-// GetMatchesByDivisionAndMatchday ruft alle Matches eines Spieltags ab
-func (d *Database) GetMatchesByDivisionAndMatchday(division, matchday int) ([]*Match, error) {
-	rows, err := d.DB.Query(
-		`SELECT id, division, matchday, team_home_id, team_away_id, 
-		 score_home, score_away, channel_id, reported_at, reported_by, created_at 
-		 FROM matches WHERE division = ? AND matchday = ? ORDER BY id`,
-		division, matchday,
+	result, err := tx.Exec(
+		`UPDATE matches 
+		 SET score_home = ?, score_away = ?, reported_at = CURRENT_TIMESTAMP, reported_by = ?, version = version + 1 
+		 WHERE id = ? AND version = ?`,
+		scoreHome, scoreAway, reportedBy, id, version,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abrufen der Matches: %w", err)
+		return fmt.Errorf("fehler beim Aktualisieren des Scores: %w", err)
 	}
-	defer rows.Close()
-
-	var matches []*Match
-	for rows.Next() {
-		match := &Match{}
-		if err := rows.Scan(
-			&match.ID, &match.Division, &match.Matchday, &match.TeamHomeID, &match.TeamAwayID,
-			&match.ScoreHome, &match.ScoreAway, &match.ChannelID, &match.ReportedAt,
-			&match.ReportedBy, &match.CreatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("fehler beim Scannen des Matches: %w", err)
+
+	// Bei PostgreSQL kann eine parallele Transaktion zwischen Lesen und UPDATE committet haben
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("fehler beim Aktualisieren des Scores: %w", err)
+	} else if n == 0 {
+		current, err := getMatchByID(tx, id)
+		if err != nil {
+			return err
 		}
-		matches = append(matches, match)
+		return &ResultConflictError{Current: current}
 	}
 
-	return matches, nil
+	entry, err := d.recordMatchChange(tx, d.actorOr(reportedBy), "match.score", before)
+	if err != nil {
+		return err
+	}
+
+	return d.commit(tx, entry)
 }
 
-// UpdateMatchScore aktualisiert das Ergebnis eines Matches
-func (d *Database) UpdateMatchScore(id, scoreHome, scoreAway int, reportedBy string) error {
-	if scoreHome < 0 || scoreHome > 4 || scoreAway < 0 || scoreAway > 4 {
-		return fmt.Errorf("scores müssen zwischen 0 und 4 liegen")
+// ClearMatchScore setzt das Ergebnis eines Matches zurück
+func (d *Database) ClearMatchScore(ctx context.Context, id int) error {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
 
-	result, err := d.DB.Exec(
+	before, err := getMatchByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
 		`UPDATE matches 
-		 SET score_home = ?, score_away = ?, reported_at = CURRENT_TIMESTAMP, reported_by = ? 
+		 SET score_home = NULL, score_away = NULL, reported_at = NULL, reported_by = NULL, version = version + 1 
 		 WHERE id = ?`,
-		scoreHome, scoreAway, reportedBy, id,
+		id,
 	)
 	if err != nil {
-		return fmt.Errorf("fehler beim Aktualisieren des Scores: %w", err)
+		return fmt.Errorf("fehler beim Zurücksetzen des Scores: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
+	entry, err := d.auditMatchChange(tx, "match.clear", before)
 	if err != nil {
-		return fmt.Errorf("fehler beim Überprüfen der aktualisierten Zeilen: %w", err)
-	}
-
-	if rows == 0 {
-		return fmt.Errorf("match mit ID %d nicht gefunden", id)
+		return err
 	}
 
-	return nil
+	return d.commit(tx, entry)
 }
 
 // UpdateMatchChannelID aktualisiert die Channel-ID eines Matches
-func (d *Database) UpdateMatchChannelID(id int, channelID string) error {
-	result, err := d.DB.Exec(
-		"UPDATE matches SET channel_id = ? WHERE id = ?",
-		channelID, id,
-	)
+func (d *Database) UpdateMatchChannelID(ctx context.Context, id int, channelID string) error {
+	tx, err := d.begin(ctx)
 	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := getMatchByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE matches SET channel_id = ? WHERE id = ?", channelID, id); err != nil {
 		return fmt.Errorf("fehler beim Aktualisieren der Channel-ID: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
+	entry, err := d.auditMatchChange(tx, "match.channel", before)
 	if err != nil {
-		return fmt.Errorf("fehler beim Überprüfen der aktualisierten Zeilen: %w", err)
+		return err
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("match mit ID %d nicht gefunden", id)
+	return d.commit(tx, entry)
+}
+
+// SetMatchTime setzt den vereinbarten Spieltermin eines Matches (nil = Termin entfernen)
+func (d *Database) SetMatchTime(ctx context.Context, id int, scheduledAt *time.Time) error {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := getMatchByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	if scheduledAt != nil {
+		value = scheduledAt.UTC()
+	}
+
+	if _, err := tx.Exec("UPDATE matches SET scheduled_at = ? WHERE id = ?", value, id); err != nil {
+		return fmt.Errorf("fehler beim Setzen des Spieltermins: %w", err)
 	}
 
-	return nil
+	entry, err := d.auditMatchChange(tx, "match.time", before)
+	if err != nil {
+		return err
+	}
+
+	return d.commit(tx, entry)
+}
+
+// MatchPlan beschreibt ein neu anzulegendes Match eines Spielplans
+type MatchPlan struct {
+	Matchday   int
+	TeamHomeID int
+	TeamAwayID *int // nil = Free Win
 }
 
 // DeleteMatchesByDivision löscht alle Matches einer Division
-func (d *Database) DeleteMatchesByDivision(division int) error {
-	_, err := d.DB.Exec("DELETE FROM matches WHERE division = ?", division)
+func (d *Database) DeleteMatchesByDivision(ctx context.Context, division int) error {
+	return d.ReplaceMatches(ctx, division, 1, nil)
+}
+
+// ReplaceMatches löscht alle Matches einer Division ab dem angegebenen Spieltag
+// und legt die übergebenen Matches in einer einzigen Transaktion neu an
+func (d *Database) ReplaceMatches(ctx context.Context, division, fromMatchday int, plans []MatchPlan) error {
+	tx, err := d.begin(ctx)
 	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := queryMatches(tx, "WHERE division = ? AND matchday >= ? ORDER BY matchday, id", division, fromMatchday)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"DELETE FROM disqualified_matches WHERE match_id IN (SELECT id FROM matches WHERE division = ? AND matchday >= ?)",
+		division, fromMatchday,
+	)
+	if err != nil {
+		return fmt.Errorf("fehler beim Löschen der gesicherten Match-Zustände: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM matches WHERE division = ? AND matchday >= ?", division, fromMatchday); err != nil {
 		return fmt.Errorf("fehler beim Löschen der Matches: %w", err)
 	}
-	return nil
+
+	var entries []*AuditEntry
+	if len(before) > 0 {
+		entry, err := d.recordAudit(tx, d.actorOr(""), "matches.delete", "division", division, matchStates(before), nil)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, plan := range plans {
+		if plan.Matchday < fromMatchday {
+			return fmt.Errorf("match für Spieltag %d liegt vor Spieltag %d", plan.Matchday, fromMatchday)
+		}
+
+		var awayID sql.NullInt64
+		if plan.TeamAwayID != nil {
+			awayID = sql.NullInt64{Int64: int64(*plan.TeamAwayID), Valid: true}
+		}
+
+		id, err := insertID(tx,
+			"INSERT INTO matches (division, matchday, team_home_id, team_away_id) VALUES (?, ?, ?, ?)",
+			division, plan.Matchday, plan.TeamHomeID, awayID,
+		)
+		if err != nil {
+			return fmt.Errorf("fehler beim Erstellen des Matches: %w", err)
+		}
+
+		match, err := getMatchByID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		entry, err := d.recordAudit(tx, d.actorOr(""), "match.create", "match", match.ID, nil, matchState(match))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	return d.commit(tx, entries...)
 }
 
 // GetMatchByChannelID ruft ein Match anhand der Channel-ID ab
-func (d *Database) GetMatchByChannelID(channelID string) (*Match, error) {
-	match := &Match{}
-	err := d.DB.QueryRow(
-		`SELECT id, division, matchday, team_home_id, team_away_id, 
-		 score_home, score_away, channel_id, reported_at, reported_by, created_at 
-		 FROM matches WHERE channel_id = ?`,
-		channelID,
-	).Scan(
-		&match.ID, &match.Division, &match.Matchday, &match.TeamHomeID, &match.TeamAwayID,
-		&match.ScoreHome, &match.ScoreAway, &match.ChannelID, &match.ReportedAt,
-		&match.ReportedBy, &match.CreatedAt,
-	)
+func (d *Database) GetMatchByChannelID(ctx context.Context, channelID string) (*Match, error) {
+	return queryMatch(d.with(ctx), errors.New("kein match für diesen channel gefunden"), "WHERE channel_id = ?", channelID)
+}
+
+// auditMatchChange liest den neuen Zustand eines Matches und schreibt einen Audit-Eintrag
+func (d *Database) auditMatchChange(tx *txn, action string, before *Match) (*AuditEntry, error) {
+	return d.recordMatchChange(tx, d.actorOr(""), action, before)
+}
 
+func (d *Database) recordMatchChange(tx *txn, actorID, action string, before *Match) (*AuditEntry, error) {
+	after, err := getMatchByID(tx, before.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("kein match für diesen channel gefunden")
-		}
-		return nil, fmt.Errorf("fehler beim Abrufen des Matches: %w", err)
+		return nil, err
 	}
-
-	return match, nil
+	return d.recordAudit(tx, actorID, action, "match", before.ID, matchState(before), matchState(after))
 }
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// StandingsMessage ist die Discord-Nachricht, in der der Bot die Tabelle einer Division pflegt
+type StandingsMessage struct {
+	Division  int
+	ChannelID string
+	MessageID string
+}
+
+// GetStandingsMessage ruft die Tabellen-Nachricht einer Division ab (nil, wenn noch keine existiert)
+func (d *Database) GetStandingsMessage(ctx context.Context, division int) (*StandingsMessage, error) {
+	msg := &StandingsMessage{}
+	err := d.with(ctx).QueryRow(
+		"SELECT division, channel_id, message_id FROM standings_messages WHERE division = ?",
+		division,
+	).Scan(&msg.Division, &msg.ChannelID, &msg.MessageID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fehler beim Abrufen der Tabellen-Nachricht: %w", err)
+	}
+
+	return msg, nil
+}
+
+// SaveStandingsMessage speichert die Tabellen-Nachricht einer Division
+func (d *Database) SaveStandingsMessage(ctx context.Context, division int, channelID, messageID string) error {
+	_, err := d.with(ctx).Exec(
+		`INSERT INTO standings_messages (division, channel_id, message_id, updated_at)
+		 VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(division) DO UPDATE SET
+		     channel_id = excluded.channel_id,
+		     message_id = excluded.message_id,
+		     updated_at = CURRENT_TIMESTAMP`,
+		division, channelID, messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("fehler beim Speichern der Tabellen-Nachricht: %w", err)
+	}
+	return nil
+}
+
+// HasMatchdayRecap prüft, ob für einen Spieltag bereits ein Rückblick gepostet wurde
+func (d *Database) HasMatchdayRecap(ctx context.Context, division, matchday int) (bool, error) {
+	var count int
+	err := d.with(ctx).QueryRow(
+		"SELECT COUNT(*) FROM matchday_recaps WHERE division = ? AND matchday = ?",
+		division, matchday,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("fehler beim Abrufen des Spieltag-Rückblicks: %w", err)
+	}
+	return count > 0, nil
+}
+
+// SaveMatchdayRecap speichert die Nachricht des Rückblicks für einen Spieltag
+func (d *Database) SaveMatchdayRecap(ctx context.Context, division, matchday int, channelID, messageID string) error {
+	_, err := d.with(ctx).Exec(
+		`INSERT INTO matchday_recaps (division, matchday, channel_id, message_id)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(division, matchday) DO UPDATE SET
+		     channel_id = excluded.channel_id,
+		     message_id = excluded.message_id`,
+		division, matchday, channelID, messageID,
+	)
+	if err != nil {
+		return fmt.Errorf("fehler beim Speichern des Spieltag-Rückblicks: %w", err)
+	}
+	return nil
+}
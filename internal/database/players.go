@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Player repräsentiert einen Spieler im Roster eines Teams
+type Player struct {
+	ID         int
+	TeamID     int
+	Name       string
+	TrackerURL string
+	Position   int
+	CreatedAt  time.Time
+}
+
+// GetPlayersByTeam ruft das Roster eines Teams ab
+func (d *Database) GetPlayersByTeam(ctx context.Context, teamID int) ([]*Player, error) {
+	players, err := queryAll(d.with(ctx), scanPlayer,
+		"SELECT id, team_id, name, tracker_url, position, created_at FROM players WHERE team_id = ? ORDER BY position, id",
+		teamID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Spieler: %w", err)
+	}
+	return players, nil
+}
+
+func scanPlayer(row rowScanner) (*Player, error) {
+	player := &Player{}
+	err := row.Scan(&player.ID, &player.TeamID, &player.Name, &player.TrackerURL, &player.Position, &player.CreatedAt)
+	return player, err
+}
+
+// ReplacePlayers ersetzt das komplette Roster eines Teams
+func (d *Database) ReplacePlayers(ctx context.Context, teamID int, players []*Player) error {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := getTeamByID(tx, teamID); err != nil {
+		return err
+	}
+
+	var before []string
+	rows, err := tx.Query("SELECT name FROM players WHERE team_id = ? ORDER BY position, id", teamID)
+	if err != nil {
+		return fmt.Errorf("fehler beim Abrufen der Spieler: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("fehler beim Scannen des Spielers: %w", err)
+		}
+		before = append(before, name)
+	}
+	rows.Close()
+
+	if _, err := tx.Exec("DELETE FROM players WHERE team_id = ?", teamID); err != nil {
+		return fmt.Errorf("fehler beim Löschen der Spieler: %w", err)
+	}
+
+	var after []string
+	for i, player := range players {
+		_, err := tx.Exec(
+			"INSERT INTO players (team_id, name, tracker_url, position) VALUES (?, ?, ?, ?)",
+			teamID, player.Name, player.TrackerURL, i+1,
+		)
+		if err != nil {
+			return fmt.Errorf("fehler beim Erstellen des Spielers '%s': %w", player.Name, err)
+		}
+		after = append(after, player.Name)
+	}
+
+	entry, err := d.recordAudit(tx, d.actorOr(""), "team.roster", "team", teamID, before, after)
+	if err != nil {
+		return err
+	}
+
+	return d.commit(tx, entry)
+}
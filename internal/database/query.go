@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// querier wird von boundDB und txn erfüllt
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// rowScanner wird von *sql.Row und *sql.Rows erfüllt
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// boundDB führt alle Abfragen mit einem festen Kontext und im Dialekt der Datenbank aus.
+// Lesende Abfragen laufen über db, schreibende über writer.
+type boundDB struct {
+	ctx     context.Context
+	db      *sql.DB
+	writer  *sql.DB
+	dialect Dialect
+}
+
+func (b boundDB) Exec(query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := retryBusy(b.ctx, func() error {
+		var err error
+		result, err = b.writer.ExecContext(b.ctx, b.dialect.rebind(query), args...)
+		return err
+	})
+	return result, err
+}
+
+func (b boundDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return b.db.QueryContext(b.ctx, b.dialect.rebind(query), args...)
+}
+
+func (b boundDB) QueryRow(query string, args ...any) *sql.Row {
+	return b.db.QueryRowContext(b.ctx, b.dialect.rebind(query), args...)
+}
+
+// with gibt einen querier zurück, dessen Abfragen beim Ablauf von ctx abgebrochen werden
+func (d *Database) with(ctx context.Context) querier {
+	return boundDB{ctx: ctx, db: d.DB, writer: d.writer, dialect: d.dialect}
+}
+
+// txn ist eine Transaktion, deren Abfragen im Dialekt der Datenbank ausgeführt werden
+type txn struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+func (t *txn) Exec(query string, args ...any) (sql.Result, error) {
+	return t.Tx.Exec(t.dialect.rebind(query), args...)
+}
+
+func (t *txn) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.Tx.Query(t.dialect.rebind(query), args...)
+}
+
+func (t *txn) QueryRow(query string, args ...any) *sql.Row {
+	return t.Tx.QueryRow(t.dialect.rebind(query), args...)
+}
+
+// begin startet eine Transaktion, die beim Ablauf von ctx zurückgerollt wird.
+// Bei SQLite sperrt sie die Datenbank sofort zum Schreiben (BEGIN IMMEDIATE).
+func (d *Database) begin(ctx context.Context) (*txn, error) {
+	var tx *sql.Tx
+	err := retryBusy(ctx, func() error {
+		var err error
+		tx, err = d.writer.BeginTx(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Starten der Transaktion: %w", err)
+	}
+	return &txn{Tx: tx, dialect: d.dialect}, nil
+}
+
+// insertID führt ein INSERT ... RETURNING id aus und gibt die neue ID zurück
+func insertID(q querier, query string, args ...any) (int, error) {
+	var id int
+	err := q.QueryRow(query+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
+
+// queryAll führt eine Abfrage aus und liest jede Zeile mit scan ein
+func queryAll[T any](q querier, scan func(rowScanner) (T, error), query string, args ...any) ([]T, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// queryOne liest genau eine Zeile mit scan ein. Gibt es keine passende Zeile, wird notFound zurückgegeben.
+func queryOne[T any](q querier, scan func(rowScanner) (T, error), notFound error, query string, args ...any) (T, error) {
+	item, err := scan(q.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		var zero T
+		return zero, notFound
+	}
+	return item, err
+}
+
+// scanInt liest eine einzelne Zahl, z.B. für SELECT DISTINCT division
+func scanInt(row rowScanner) (int, error) {
+	var v int
+	err := row.Scan(&v)
+	return v, err
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// TeamRepository verwaltet Teams, ihre Roster und ihren Status in der Liga.
+// Alle Methoden brechen ab, sobald ctx abläuft.
+type TeamRepository interface {
+	CreateTeam(ctx context.Context, name string, division int) (*Team, error)
+	GetTeamByID(ctx context.Context, id int) (*Team, error)
+	GetTeamByName(ctx context.Context, name string) (*Team, error)
+	GetTeamByRoleID(ctx context.Context, roleID string) (*Team, error)
+	GetAllTeams(ctx context.Context) ([]*Team, error)
+	GetTeamsByDivision(ctx context.Context, division int) ([]*Team, error)
+	GetDivisions(ctx context.Context) ([]int, error)
+	UpdateTeam(ctx context.Context, id int, name string, division int) error
+	UpdateTeamRoleID(ctx context.Context, id int, roleID string) error
+	DeleteTeam(ctx context.Context, id int) error
+
+	GetPlayersByTeam(ctx context.Context, teamID int) ([]*Player, error)
+	ReplacePlayers(ctx context.Context, teamID int, players []*Player) error
+
+	DisqualifyTeam(ctx context.Context, teamID int) error
+	RequalifyTeam(ctx context.Context, teamID int, restoreMatches bool) ([]*Match, []*Match, error)
+	GetDisqualifiedMatches(ctx context.Context, teamID int) ([]*DisqualifiedMatch, error)
+	WithdrawTeam(ctx context.Context, teamID int) ([]*Match, []*Match, error)
+	AssignByeSlot(ctx context.Context, teamID, fromMatchday int) ([]*Match, error)
+	LateEntry(ctx context.Context, name string, division int, roleID string, fromMatchday int) (*Team, []*Match, error)
+}
+
+// MatchRepository verwaltet Spielpläne, Ergebnisse und Match-Channels.
+// Alle Methoden brechen ab, sobald ctx abläuft.
+type MatchRepository interface {
+	CreateMatch(ctx context.Context, division, matchday, teamHomeID int, teamAwayID *int) (*Match, error)
+	GetMatchByID(ctx context.Context, id int) (*Match, error)
+	GetMatchByChannelID(ctx context.Context, channelID string) (*Match, error)
+	GetAllMatches(ctx context.Context) ([]*Match, error)
+	GetMatchesByDivision(ctx context.Context, division int) ([]*Match, error)
+	GetMatchesByDivisionAndMatchday(ctx context.Context, division, matchday int) ([]*Match, error)
+	GetMatchesByTeam(ctx context.Context, teamID int) ([]*Match, error)
+	ReplaceMatches(ctx context.Context, division, fromMatchday int, plans []MatchPlan) error
+	DeleteMatchesByDivision(ctx context.Context, division int) error
+
+	UpdateMatchScore(ctx context.Context, id, version, scoreHome, scoreAway int, reportedBy string) error
+	ClearMatchScore(ctx context.Context, id int) error
+	UpdateMatchChannelID(ctx context.Context, id int, channelID string) error
+	SetMatchTime(ctx context.Context, id int, scheduledAt *time.Time) error
+}
+
+// Repository fasst Teams und Matches zusammen, z.B. für Tabellen und Übersichten
+type Repository interface {
+	TeamRepository
+	MatchRepository
+}
+
+// AuditRepository liest das Audit-Log, neueste Einträge zuerst
+type AuditRepository interface {
+	GetAuditLogByMatch(ctx context.Context, matchID, limit int) ([]*AuditEntry, error)
+	GetAuditLogByTeam(ctx context.Context, teamID, limit int) ([]*AuditEntry, error)
+}
+
+// MessageRepository merkt sich die Discord-Nachrichten, die der Bot später aktualisiert
+type MessageRepository interface {
+	GetStandingsMessage(ctx context.Context, division int) (*StandingsMessage, error)
+	SaveStandingsMessage(ctx context.Context, division int, channelID, messageID string) error
+	HasMatchdayRecap(ctx context.Context, division, matchday int) (bool, error)
+	SaveMatchdayRecap(ctx context.Context, division, matchday int, channelID, messageID string) error
+}
+
+// BackupRepository legt Sicherungen der Datenbank an
+type BackupRepository interface {
+	CreateBackup(ctx context.Context, dir string, keep int) (string, error)
+}
+
+// Store ist der Datenzugriff der Discord-Commands. WithActor und WithReason geben eine Sicht
+// zurück, deren Änderungen im Audit-Log dem Discord-User mit der Begründung zugeordnet werden;
+// WithEvent eine Sicht, deren Änderung zusammen mit einem Webhook-Event gespeichert wird.
+type Store interface {
+	Repository
+	AuditRepository
+	MessageRepository
+
+	WithActor(actorID string) Store
+	WithReason(reason string) Store
+	WithEvent(eventType string, data any) Store
+}
+
+// Database implementiert alle Repositories für SQLite und PostgreSQL
+var (
+	_ Store            = (*Database)(nil)
+	_ BackupRepository = (*Database)(nil)
+)
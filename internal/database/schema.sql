@@ -6,6 +6,8 @@ CREATE TABLE IF NOT EXISTS teams (
     role_id TEXT,
     is_disqualified BOOLEAN DEFAULT 0,
     disqualified_at DATETIME,
+    is_withdrawn BOOLEAN DEFAULT 0,
+    withdrawn_at DATETIME,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
@@ -20,6 +22,19 @@ BEGIN
     UPDATE teams SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
 END;
 
+-- Spieler Tabelle (Roster der Teams)
+CREATE TABLE IF NOT EXISTS players (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    tracker_url TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_players_team ON players(team_id);
+
 -- Matches Tabelle
 CREATE TABLE IF NOT EXISTS matches (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -32,6 +47,8 @@ CREATE TABLE IF NOT EXISTS matches (
     channel_id TEXT,
     reported_at DATETIME,
     reported_by TEXT,
+    scheduled_at DATETIME,
+    version INTEGER NOT NULL DEFAULT 0,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (team_home_id) REFERENCES teams(id),
     FOREIGN KEY (team_away_id) REFERENCES teams(id)
@@ -41,3 +58,82 @@ CREATE TABLE IF NOT EXISTS matches (
 CREATE INDEX IF NOT EXISTS idx_matches_division ON matches(division);
 CREATE INDEX IF NOT EXISTS idx_matches_matchday ON matches(matchday);
 CREATE INDEX IF NOT EXISTS idx_matches_teams ON matches(team_home_id, team_away_id);
+
+-- Zustand der Matches vor einer Disqualifikation (für /requalify restore_matches)
+CREATE TABLE IF NOT EXISTS disqualified_matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL,
+    match_id INTEGER NOT NULL,
+    prev_score_home INTEGER,
+    prev_score_away INTEGER,
+    prev_reported_at DATETIME,
+    prev_reported_by TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (team_id, match_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (match_id) REFERENCES matches(id)
+);
+
+-- Audit-Log aller Änderungen am Liga-Zustand
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before_json TEXT,
+    after_json TEXT,
+    reason TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+
+-- Warteschlange der ausgehenden Webhooks (eine Zeile pro Event und Empfänger)
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    url TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, delivered, failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+
+-- Protokoll aller Zustellversuche
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery ON webhook_attempts(delivery_id);
+
+-- Live-Tabellen: vom Bot gepflegte Nachricht pro Division im Tabellen-Channel
+CREATE TABLE IF NOT EXISTS standings_messages (
+    division INTEGER PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bereits gepostete Spieltag-Rückblicke (höchstens einer pro Division und Spieltag)
+CREATE TABLE IF NOT EXISTS matchday_recaps (
+    division INTEGER NOT NULL,
+    matchday INTEGER NOT NULL,
+    channel_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (division, matchday)
+);
@@ -0,0 +1,141 @@
+-- PostgreSQL-Schema, entspricht schema.sql inklusive aller nachträglich ergänzten Spalten
+
+-- Teams Tabelle
+CREATE TABLE IF NOT EXISTS teams (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    division INTEGER NOT NULL,
+    role_id TEXT,
+    is_disqualified BOOLEAN DEFAULT FALSE,
+    disqualified_at TIMESTAMPTZ,
+    is_withdrawn BOOLEAN DEFAULT FALSE,
+    withdrawn_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index für schnellere Division-Abfragen
+CREATE INDEX IF NOT EXISTS idx_teams_division ON teams(division);
+
+-- Trigger für updated_at
+CREATE OR REPLACE FUNCTION update_teams_timestamp() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_teams_timestamp ON teams;
+CREATE TRIGGER update_teams_timestamp
+    BEFORE UPDATE ON teams
+    FOR EACH ROW EXECUTE FUNCTION update_teams_timestamp();
+
+-- Spieler Tabelle (Roster der Teams)
+CREATE TABLE IF NOT EXISTS players (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id),
+    name TEXT NOT NULL,
+    tracker_url TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_players_team ON players(team_id);
+
+-- Matches Tabelle
+CREATE TABLE IF NOT EXISTS matches (
+    id SERIAL PRIMARY KEY,
+    division INTEGER NOT NULL,
+    matchday INTEGER NOT NULL,
+    team_home_id INTEGER NOT NULL REFERENCES teams(id),
+    team_away_id INTEGER REFERENCES teams(id),
+    score_home INTEGER CHECK(score_home IS NULL OR (score_home >= 0 AND score_home <= 4)),
+    score_away INTEGER CHECK(score_away IS NULL OR (score_away >= 0 AND score_away <= 4)),
+    channel_id TEXT,
+    reported_at TIMESTAMPTZ,
+    reported_by TEXT,
+    scheduled_at TIMESTAMPTZ,
+    version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indices für Matches
+CREATE INDEX IF NOT EXISTS idx_matches_division ON matches(division);
+CREATE INDEX IF NOT EXISTS idx_matches_matchday ON matches(matchday);
+CREATE INDEX IF NOT EXISTS idx_matches_teams ON matches(team_home_id, team_away_id);
+
+-- Zustand der Matches vor einer Disqualifikation (für /requalify restore_matches)
+CREATE TABLE IF NOT EXISTS disqualified_matches (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id),
+    match_id INTEGER NOT NULL REFERENCES matches(id),
+    prev_score_home INTEGER,
+    prev_score_away INTEGER,
+    prev_reported_at TIMESTAMPTZ,
+    prev_reported_by TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (team_id, match_id)
+);
+
+-- Audit-Log aller Änderungen am Liga-Zustand
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    actor_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before_json TEXT,
+    after_json TEXT,
+    reason TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id);
+
+-- Warteschlange der ausgehenden Webhooks (eine Zeile pro Event und Empfänger)
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    url TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, delivered, failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+
+-- Protokoll aller Zustellversuche
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id SERIAL PRIMARY KEY,
+    delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries(id),
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery ON webhook_attempts(delivery_id);
+
+-- Live-Tabellen: vom Bot gepflegte Nachricht pro Division im Tabellen-Channel
+CREATE TABLE IF NOT EXISTS standings_messages (
+    division INTEGER PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bereits gepostete Spieltag-Rückblicke (höchstens einer pro Division und Spieltag)
+CREATE TABLE IF NOT EXISTS matchday_recaps (
+    division INTEGER NOT NULL,
+    matchday INTEGER NOT NULL,
+    channel_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (division, matchday)
+);
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteParams sind die Verbindungsoptionen für jede SQLite-Verbindung:
+// WAL erlaubt Lesern (Bot, API, Flask) parallel zum Schreiber zu arbeiten, busy_timeout wartet auf
+// Sperren anderer Prozesse statt sofort SQLITE_BUSY zu melden, und Transaktionen sperren mit
+// BEGIN IMMEDIATE schon beim Start, damit sie nicht erst beim ersten Schreiben scheitern.
+var sqliteParams = []string{
+	"_journal_mode=WAL",
+	"_synchronous=NORMAL",
+	"_busy_timeout=5000",
+	"_foreign_keys=on",
+	"_txlock=immediate",
+}
+
+const (
+	// busyRetries ist die Anzahl der Wiederholungen, wenn eine Sperre trotz busy_timeout nicht frei wird
+	busyRetries = 4
+
+	// busyBackoff ist die Wartezeit vor der ersten Wiederholung, sie verdoppelt sich mit jedem Versuch
+	busyBackoff = 50 * time.Millisecond
+)
+
+// sqliteDSN ergänzt den Pfad um die Verbindungsoptionen, ohne bereits gesetzte Optionen zu überschreiben
+func sqliteDSN(dsn string) string {
+	var params []string
+	for _, param := range sqliteParams {
+		key := param[:strings.Index(param, "=")+1]
+		if !strings.Contains(dsn, key) {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return dsn
+	}
+
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + strings.Join(params, "&")
+}
+
+// openSQLite öffnet zwei Verbindungspools auf dieselbe Datei: beliebig viele Leser und genau einen
+// Schreiber. Schreibende Transaktionen der Handler warten so in database/sql aufeinander, statt
+// sich gegenseitig mit SQLITE_BUSY abzuweisen.
+// In-Memory-Datenbanken (Tests) kennen kein WAL und teilen sich Sperren pro Tabelle, dort gibt es
+// nur einen Pool mit einer Verbindung.
+func openSQLite(dsn string) (readers, writer *sql.DB, err error) {
+	dsn = sqliteDSN(dsn)
+
+	readers, err = sql.Open(SQLite.driver(), dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.Contains(dsn, ":memory:") || strings.Contains(dsn, "mode=memory") {
+		readers.SetMaxOpenConns(1)
+		return readers, readers, nil
+	}
+
+	writer, err = sql.Open(SQLite.driver(), dsn)
+	if err != nil {
+		readers.Close()
+		return nil, nil, err
+	}
+	writer.SetMaxOpenConns(1)
+	// Die Verbindung offen halten, damit sie nicht bei jedem Schreiben neu aufgebaut wird
+	writer.SetConnMaxIdleTime(0)
+	writer.SetConnMaxLifetime(0)
+
+	return readers, writer, nil
+}
+
+// isBusy prüft, ob eine Abfrage an einer Sperre eines anderen Prozesses oder einer anderen Verbindung gescheitert ist
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// retryBusy führt fn erneut aus, solange sie mit SQLITE_BUSY scheitert, höchstens busyRetries Mal
+func retryBusy(ctx context.Context, fn func() error) error {
+	backoff := busyBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt == busyRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// checkForeignKeys meldet Zeilen, die schon vor dem Einschalten der Fremdschlüssel auf
+// gelöschte Teams oder Matches verwiesen haben. Sie bleiben erhalten, bis sie geändert werden.
+func (d *Database) checkForeignKeys(ctx context.Context) ([]string, error) {
+	rows, err := d.writer.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("fehler bei der Prüfung der Fremdschlüssel: %w", err)
+	}
+	defer rows.Close()
+
+	var violations []string
+	for rows.Next() {
+		var (
+			table, parent string
+			rowID         sql.NullInt64
+			fkID          int
+		)
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, fmt.Errorf("fehler bei der Prüfung der Fremdschlüssel: %w", err)
+		}
+		violations = append(violations, fmt.Sprintf("%s (Zeile %d) verweist auf fehlenden Eintrag in %s", table, rowID.Int64, parent))
+	}
+	return violations, rows.Err()
+}
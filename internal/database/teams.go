@@ -1,11 +1,19 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 )
 
+// DisqualifiedReporter wird als reported_by bei Matches eingetragen, die durch eine Disqualifikation gewertet wurden
+const DisqualifiedReporter = "System (Disqualified)"
+
+// ErrTeamNotFound wird (umschlossen) zurückgegeben, wenn ein gesuchtes Team nicht existiert
+var ErrTeamNotFound = errors.New("nicht gefunden")
+
 // Team repräsentiert ein Team in der Datenbank
 type Team struct {
 	ID             int
@@ -14,13 +22,51 @@ type Team struct {
 	RoleID         string
 	IsDisqualified bool
 	DisqualifiedAt sql.NullTime
+	IsWithdrawn    bool
+	WithdrawnAt    sql.NullTime
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
 
+// teamColumns sind die Spalten, die scanTeam erwartet
+const teamColumns = "id, name, division, role_id, is_disqualified, disqualified_at, is_withdrawn, withdrawn_at, created_at, updated_at"
+
+func scanTeam(row rowScanner) (*Team, error) {
+	team := &Team{}
+	err := row.Scan(
+		&team.ID, &team.Name, &team.Division, &team.RoleID, &team.IsDisqualified, &team.DisqualifiedAt,
+		&team.IsWithdrawn, &team.WithdrawnAt, &team.CreatedAt, &team.UpdatedAt,
+	)
+	return team, err
+}
+
+// queryTeams ruft alle Teams ab, die auf die angegebene WHERE/ORDER-Klausel passen
+func queryTeams(q querier, clause string, args ...any) ([]*Team, error) {
+	teams, err := queryAll(q, scanTeam, "SELECT "+teamColumns+" FROM teams "+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Teams: %w", err)
+	}
+	return teams, nil
+}
+
+// queryTeam ruft das Team ab, das auf die WHERE-Klausel passt. Gibt es keins, wird notFound zurückgegeben.
+func queryTeam(q querier, notFound error, clause string, args ...any) (*Team, error) {
+	team, err := queryOne(q, scanTeam, notFound, "SELECT "+teamColumns+" FROM teams "+clause, args...)
+	if err != nil && err != notFound {
+		return nil, fmt.Errorf("fehler beim Abrufen des Teams: %w", err)
+	}
+	return team, err
+}
+
 // CreateTeam erstellt ein neues Team
-func (d *Database) CreateTeam(name string, division int) (*Team, error) {
-	result, err := d.DB.Exec(
+func (d *Database) CreateTeam(ctx context.Context, name string, division int) (*Team, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertID(tx,
 		"INSERT INTO teams (name, division, role_id) VALUES (?, ?, ?)",
 		name, division, "",
 	)
@@ -28,187 +74,184 @@ func (d *Database) CreateTeam(name string, division int) (*Team, error) {
 		return nil, fmt.Errorf("fehler beim Erstellen des Teams: %w", err)
 	}
 
-	id, err := result.LastInsertId()
+	team, err := getTeamByID(tx, id)
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abrufen der Team-ID: %w", err)
+		return nil, err
 	}
 
-	return d.GetTeamByID(int(id))
-}
-
-// GetTeamByID ruft ein Team anhand der ID ab
-func (d *Database) GetTeamByID(id int) (*Team, error) {
-	team := &Team{}
-	err := d.DB.QueryRow(
-		"SELECT id, name, division, role_id, is_disqualified, disqualified_at, created_at, updated_at FROM teams WHERE id = ?",
-		id,
-	).Scan(&team.ID, &team.Name, &team.Division, &team.RoleID, &team.IsDisqualified, &team.DisqualifiedAt, &team.CreatedAt, &team.UpdatedAt)
-
+	entry, err := d.recordAudit(tx, d.actorOr(""), "team.create", "team", team.ID, nil, teamState(team))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("team mit ID %d nicht gefunden", id)
-		}
-		return nil, fmt.Errorf("fehler beim Abrufen des Teams: %w", err)
+		return nil, err
+	}
+
+	if err := d.commit(tx, entry); err != nil {
+		return nil, err
 	}
 
 	return team, nil
 }
 
-// GetTeamByName ruft ein Team anhand des Namens ab
-func (d *Database) GetTeamByName(name string) (*Team, error) {
-	team := &Team{}
-	err := d.DB.QueryRow(
-		"SELECT id, name, division, role_id, is_disqualified, disqualified_at, created_at, updated_at FROM teams WHERE name = ?",
-		name,
-	).Scan(&team.ID, &team.Name, &team.Division, &team.RoleID, &team.IsDisqualified, &team.DisqualifiedAt, &team.CreatedAt, &team.UpdatedAt)
+// GetTeamByID ruft ein Team anhand der ID ab
+func (d *Database) GetTeamByID(ctx context.Context, id int) (*Team, error) {
+	return getTeamByID(d.with(ctx), id)
+}
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("team '%s' nicht gefunden", name)
-		}
-		return nil, fmt.Errorf("fehler beim Abrufen des Teams: %w", err)
-	}
+func getTeamByID(q querier, id int) (*Team, error) {
+	return queryTeam(q, fmt.Errorf("team mit ID %d %w", id, ErrTeamNotFound), "WHERE id = ?", id)
+}
 
-	return team, nil
+// GetTeamByName ruft ein Team anhand des Namens ab
+func (d *Database) GetTeamByName(ctx context.Context, name string) (*Team, error) {
+	return queryTeam(d.with(ctx), fmt.Errorf("team '%s' %w", name, ErrTeamNotFound), "WHERE name = ?", name)
 }
 
 // GetAllTeams ruft alle Teams ab
-func (d *Database) GetAllTeams() ([]*Team, error) {
-	rows, err := d.DB.Query(
-		"SELECT id, name, division, role_id, is_disqualified, disqualified_at, created_at, updated_at FROM teams ORDER BY division, name",
-	)
-	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abrufen der Teams: %w", err)
-	}
-	defer rows.Close()
-
-	var teams []*Team
-	for rows.Next() {
-		team := &Team{}
-		if err := rows.Scan(&team.ID, &team.Name, &team.Division, &team.RoleID, &team.IsDisqualified, &team.DisqualifiedAt, &team.CreatedAt, &team.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("fehler beim Scannen des Teams: %w", err)
-		}
-		teams = append(teams, team)
-	}
-
-	return teams, nil
+func (d *Database) GetAllTeams(ctx context.Context) ([]*Team, error) {
+	return queryTeams(d.with(ctx), "ORDER BY division, name")
 }
 
 // GetTeamsByDivision ruft alle Teams einer Division ab
-func (d *Database) GetTeamsByDivision(division int) ([]*Team, error) {
-	rows, err := d.DB.Query(
-		"SELECT id, name, division, role_id, is_disqualified, disqualified_at, created_at, updated_at FROM teams WHERE division = ? ORDER BY name",
-		division,
-	)
+func (d *Database) GetTeamsByDivision(ctx context.Context, division int) ([]*Team, error) {
+	return queryTeams(d.with(ctx), "WHERE division = ? ORDER BY name", division)
+}
+
+// GetDivisions ruft alle Divisionen ab, in denen Teams spielen
+func (d *Database) GetDivisions(ctx context.Context) ([]int, error) {
+	divisions, err := queryAll(d.with(ctx), scanInt, "SELECT DISTINCT division FROM teams ORDER BY division")
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Abrufen der Teams: %w", err)
+		return nil, fmt.Errorf("fehler beim Abrufen der Divisionen: %w", err)
 	}
-	defer rows.Close()
+	return divisions, nil
+}
 
-	var teams []*Team
-	for rows.Next() {
-		team := &Team{}
-		if err := rows.Scan(&team.ID, &team.Name, &team.Division, &team.RoleID, &team.IsDisqualified, &team.DisqualifiedAt, &team.CreatedAt, &team.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("fehler beim Scannen des Teams: %w", err)
-		}
-		teams = append(teams, team)
-	}
+// UpdateTeam aktualisiert ein Team
+func (d *Database) UpdateTeam(ctx context.Context, id int, name string, division int) error {
+	return d.updateTeam(ctx, id, "team.update", "fehler beim Aktualisieren des Teams",
+		"UPDATE teams SET name = ?, division = ? WHERE id = ?", name, division, id)
+}
 
-	return teams, nil
+// UpdateTeamRoleID aktualisiert die Discord Rollen-ID eines Teams
+func (d *Database) UpdateTeamRoleID(ctx context.Context, id int, roleID string) error {
+	return d.updateTeam(ctx, id, "team.role", "fehler beim Aktualisieren der Rollen-ID",
+		"UPDATE teams SET role_id = ? WHERE id = ?", roleID, id)
 }
 
-// UpdateTeam aktualisiert ein Team
-func (d *Database) UpdateTeam(id int, name string, division int) error {
-	result, err := d.DB.Exec(
-		"UPDATE teams SET name = ?, division = ? WHERE id = ?",
-		name, division, id,
-	)
+// updateTeam führt ein UPDATE auf einem Team aus und protokolliert den Vorher-/Nachher-Zustand
+func (d *Database) updateTeam(ctx context.Context, id int, action, errMsg, query string, args ...any) error {
+	tx, err := d.begin(ctx)
 	if err != nil {
-		return fmt.Errorf("fehler beim Aktualisieren des Teams: %w", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := getTeamByID(tx, id)
 	if err != nil {
-		return fmt.Errorf("fehler beim Überprüfen der aktualisierten Zeilen: %w", err)
+		return err
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("%s: %w", errMsg, err)
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("team mit ID %d nicht gefunden", id)
+	entry, err := d.auditTeamChange(tx, action, before)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return d.commit(tx, entry)
 }
 
-// UpdateTeamRoleID aktualisiert die Discord Rollen-ID eines Teams
-func (d *Database) UpdateTeamRoleID(id int, roleID string) error {
-	result, err := d.DB.Exec(
-		"UPDATE teams SET role_id = ? WHERE id = ?",
-		roleID, id,
-	)
+// DeleteTeam löscht ein Team
+func (d *Database) DeleteTeam(ctx context.Context, id int) error {
+	tx, err := d.begin(ctx)
 	if err != nil {
-		return fmt.Errorf("fehler beim Aktualisieren der Rollen-ID: %w", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	before, err := getTeamByID(tx, id)
 	if err != nil {
-		return fmt.Errorf("fehler beim Überprüfen der aktualisierten Zeilen: %w", err)
+		return err
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("team mit ID %d nicht gefunden", id)
+	if _, err := tx.Exec("DELETE FROM disqualified_matches WHERE team_id = ?", id); err != nil {
+		return fmt.Errorf("fehler beim Löschen der gesicherten Match-Zustände: %w", err)
 	}
 
-	return nil
-}
+	if _, err := tx.Exec("DELETE FROM players WHERE team_id = ?", id); err != nil {
+		return fmt.Errorf("fehler beim Löschen der Spieler: %w", err)
+	}
 
-// DeleteTeam löscht ein Team
-func (d *Database) DeleteTeam(id int) error {
-	result, err := d.DB.Exec("DELETE FROM teams WHERE id = ?", id)
-	if err != nil {
+	if _, err := tx.Exec("DELETE FROM teams WHERE id = ?", id); err != nil {
 		return fmt.Errorf("fehler beim Löschen des Teams: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
+	entry, err := d.recordAudit(tx, d.actorOr(""), "team.delete", "team", id, teamState(before), nil)
 	if err != nil {
-		return fmt.Errorf("fehler beim Überprüfen der gelöschten Zeilen: %w", err)
-	}
-
-	if rows == 0 {
-		return fmt.Errorf("team mit ID %d nicht gefunden", id)
+		return err
 	}
 
-	return nil
+	return d.commit(tx, entry)
 }
 
-// GetTeamByRoleID ruft ein Team anhand der Discord Rollen-ID ab
-func (d *Database) GetTeamByRoleID(roleID string) (*Team, error) {
-	team := &Team{}
-	err := d.DB.QueryRow(
-		"SELECT id, name, division, role_id, is_disqualified, disqualified_at, created_at, updated_at FROM teams WHERE role_id = ?",
-		roleID,
-	).Scan(&team.ID, &team.Name, &team.Division, &team.RoleID, &team.IsDisqualified, &team.DisqualifiedAt, &team.CreatedAt, &team.UpdatedAt)
-
+// auditTeamChange liest den neuen Zustand eines Teams und schreibt einen Audit-Eintrag
+func (d *Database) auditTeamChange(tx *txn, action string, before *Team) (*AuditEntry, error) {
+	after, err := getTeamByID(tx, before.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("team mit Rollen-ID %s nicht gefunden", roleID)
-		}
-		return nil, fmt.Errorf("fehler beim Abrufen des Teams: %w", err)
+		return nil, err
 	}
+	return d.recordAudit(tx, d.actorOr(""), action, "team", before.ID, teamState(before), teamState(after))
+}
 
-	return team, nil
+// GetTeamByRoleID ruft ein Team anhand der Discord Rollen-ID ab
+func (d *Database) GetTeamByRoleID(ctx context.Context, roleID string) (*Team, error) {
+	return queryTeam(d.with(ctx), fmt.Errorf("team mit Rollen-ID %s %w", roleID, ErrTeamNotFound), "WHERE role_id = ?", roleID)
 }
 
 // DisqualifyTeam disqualifiziert ein Team und setzt alle Matches auf 0:3
-func (d *Database) DisqualifyTeam(teamID int) error {
-	tx, err := d.DB.Begin()
+func (d *Database) DisqualifyTeam(ctx context.Context, teamID int) error {
+	tx, err := d.begin(ctx)
 	if err != nil {
-		return fmt.Errorf("fehler beim Starten der Transaktion: %w", err)
+		return err
 	}
 	defer tx.Rollback()
 
+	before, err := getTeamByID(tx, teamID)
+	if err != nil {
+		return err
+	}
+
+	// Offene Matches vor dem Überschreiben merken
+	openMatches, err := queryMatches(tx,
+		"WHERE (team_home_id = ? OR team_away_id = ?) AND (score_home IS NULL OR score_away IS NULL) ORDER BY id",
+		teamID, teamID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Vorherigen Zustand der Matches für eine spätere Wiederherstellung sichern
+	for _, match := range openMatches {
+		_, err = tx.Exec(
+			`INSERT INTO disqualified_matches
+			 (team_id, match_id, prev_score_home, prev_score_away, prev_reported_at, prev_reported_by)
+			 VALUES (?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(team_id, match_id) DO UPDATE SET
+			     prev_score_home = excluded.prev_score_home,
+			     prev_score_away = excluded.prev_score_away,
+			     prev_reported_at = excluded.prev_reported_at,
+			     prev_reported_by = excluded.prev_reported_by,
+			     created_at = CURRENT_TIMESTAMP`,
+			teamID, match.ID, match.ScoreHome, match.ScoreAway, match.ReportedAt, match.ReportedBy,
+		)
+		if err != nil {
+			return fmt.Errorf("fehler beim Sichern des Match-Zustands: %w", err)
+		}
+	}
+
 	// Team als disqualified markieren
 	_, err = tx.Exec(
-		"UPDATE teams SET is_disqualified = 1, disqualified_at = CURRENT_TIMESTAMP WHERE id = ?",
+		"UPDATE teams SET is_disqualified = TRUE, disqualified_at = CURRENT_TIMESTAMP WHERE id = ?",
 		teamID,
 	)
 	if err != nil {
@@ -221,9 +264,10 @@ func (d *Database) DisqualifyTeam(teamID int) error {
 		UPDATE matches 
 		SET score_home = 0, score_away = 3, 
 		    reported_at = CURRENT_TIMESTAMP, 
-		    reported_by = 'System (Disqualified)'
+		    reported_by = ?,
+		    version = version + 1
 		WHERE team_home_id = ? AND (score_home IS NULL OR score_away IS NULL)
-	`, teamID)
+	`, DisqualifiedReporter, teamID)
 	if err != nil {
 		return fmt.Errorf("fehler beim Aktualisieren der Home Matches: %w", err)
 	}
@@ -233,38 +277,216 @@ func (d *Database) DisqualifyTeam(teamID int) error {
 		UPDATE matches 
 		SET score_home = 3, score_away = 0,
 		    reported_at = CURRENT_TIMESTAMP,
-		    reported_by = 'System (Disqualified)'
+		    reported_by = ?,
+		    version = version + 1
 		WHERE team_away_id = ? AND (score_home IS NULL OR score_away IS NULL)
-	`, teamID)
+	`, DisqualifiedReporter, teamID)
 	if err != nil {
 		return fmt.Errorf("fehler beim Aktualisieren der Away Matches: %w", err)
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("fehler beim Commit der Transaktion: %w", err)
+	entry, err := d.auditTeamChange(tx, "team.disqualify", before)
+	if err != nil {
+		return err
+	}
+	entries := []*AuditEntry{entry}
+
+	for _, match := range openMatches {
+		entry, err := d.auditMatchChange(tx, "match.disqualify", match)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
 	}
 
-	return nil
+	return d.commit(tx, entries...)
 }
 
-// RequalifyTeam hebt die Disqualifikation auf (setzt Matches NICHT zurück)
-func (d *Database) RequalifyTeam(teamID int) error {
-	result, err := d.DB.Exec(
-		"UPDATE teams SET is_disqualified = 0, disqualified_at = NULL WHERE id = ?",
-		teamID,
-	)
+// RequalifyTeam hebt die Disqualifikation auf.
+// Mit restoreMatches werden alle durch die Disqualifikation gewerteten Matches auf ihren
+// vorherigen Zustand zurückgesetzt, sofern sie seitdem nicht anderweitig geändert wurden.
+// Ist der Gegner ebenfalls disqualifiziert, bleibt das Match gewertet: Es wird gegen den Gegner
+// gewertet und der gesicherte Zustand geht auf dessen Disqualifikation über.
+// Ist das Team nicht mehr disqualifiziert, werden nur die Matches wiederhergestellt.
+// Zurückgegeben werden die wiederhergestellten und die an den Gegner übergebenen Matches.
+func (d *Database) RequalifyTeam(ctx context.Context, teamID int, restoreMatches bool) ([]*Match, []*Match, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := getTeamByID(tx, teamID)
 	if err != nil {
-		return fmt.Errorf("fehler beim Requalifizieren des Teams: %w", err)
+		return nil, nil, err
+	}
+
+	var entries []*AuditEntry
+	if before.IsDisqualified {
+		_, err = tx.Exec(
+			"UPDATE teams SET is_disqualified = FALSE, disqualified_at = NULL WHERE id = ?",
+			teamID,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fehler beim Requalifizieren des Teams: %w", err)
+		}
+
+		entry, err := d.auditTeamChange(tx, "team.requalify", before)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	var restored, transferred []*Match
+	if restoreMatches {
+		disqualified, err := getDisqualifiedMatches(tx, teamID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, dm := range disqualified {
+			match, err := getMatchByID(tx, dm.MatchID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// Nur Matches zurücksetzen, die noch das Disqualifikations-Ergebnis tragen
+			if match.ReportedBy.String != DisqualifiedReporter {
+				continue
+			}
+
+			opponent, err := disqualifiedOpponent(tx, match, teamID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if opponent != nil {
+				// Der Gegner verliert jetzt 0:3, der Zustand vor der ersten Disqualifikation bleibt für ihn gesichert
+				scoreHome, scoreAway := 3, 0
+				if match.TeamHomeID == opponent.ID {
+					scoreHome, scoreAway = 0, 3
+				}
+				_, err = tx.Exec(
+					`UPDATE matches
+					 SET score_home = ?, score_away = ?, reported_at = CURRENT_TIMESTAMP, reported_by = ?, version = version + 1
+					 WHERE id = ?`,
+					scoreHome, scoreAway, DisqualifiedReporter, match.ID,
+				)
+				if err != nil {
+					return nil, nil, fmt.Errorf("fehler beim Werten des Matches gegen %s: %w", opponent.Name, err)
+				}
+				_, err = tx.Exec(
+					"UPDATE disqualified_matches SET team_id = ? WHERE team_id = ? AND match_id = ?",
+					opponent.ID, teamID, match.ID,
+				)
+				if err != nil {
+					return nil, nil, fmt.Errorf("fehler beim Übertragen des gesicherten Match-Zustands: %w", err)
+				}
+
+				entry, err := d.auditMatchChange(tx, "match.disqualify", match)
+				if err != nil {
+					return nil, nil, err
+				}
+				entries = append(entries, entry)
+
+				after, err := getMatchByID(tx, match.ID)
+				if err != nil {
+					return nil, nil, err
+				}
+				transferred = append(transferred, after)
+				continue
+			}
+
+			_, err = tx.Exec(
+				`UPDATE matches
+				 SET score_home = ?, score_away = ?, reported_at = ?, reported_by = ?, version = version + 1
+				 WHERE id = ?`,
+				dm.PrevScoreHome, dm.PrevScoreAway, dm.PrevReportedAt, dm.PrevReportedBy, dm.MatchID,
+			)
+			if err != nil {
+				return nil, nil, fmt.Errorf("fehler beim Wiederherstellen des Matches: %w", err)
+			}
+
+			entry, err := d.auditMatchChange(tx, "match.restore", match)
+			if err != nil {
+				return nil, nil, err
+			}
+			entries = append(entries, entry)
+
+			restoredMatch, err := getMatchByID(tx, dm.MatchID)
+			if err != nil {
+				return nil, nil, err
+			}
+			restored = append(restored, restoredMatch)
+		}
+
+		if _, err := tx.Exec("DELETE FROM disqualified_matches WHERE team_id = ?", teamID); err != nil {
+			return nil, nil, fmt.Errorf("fehler beim Löschen der gesicherten Match-Zustände: %w", err)
+		}
+	}
+
+	if err := d.commit(tx, entries...); err != nil {
+		return nil, nil, err
+	}
+
+	return restored, transferred, nil
+}
+
+// disqualifiedOpponent gibt den Gegner von teamID in einem Match zurück, falls er disqualifiziert ist
+func disqualifiedOpponent(q querier, match *Match, teamID int) (*Team, error) {
+	if match.IsBye() {
+		return nil, nil
+	}
+
+	opponentID := match.TeamHomeID
+	if opponentID == teamID {
+		opponentID = int(match.TeamAwayID.Int64)
 	}
 
-	rows, err := result.RowsAffected()
+	opponent, err := getTeamByID(q, opponentID)
 	if err != nil {
-		return fmt.Errorf("fehler beim Überprüfen der aktualisierten Zeilen: %w", err)
+		return nil, err
 	}
+	if !opponent.IsDisqualified {
+		return nil, nil
+	}
+	return opponent, nil
+}
 
-	if rows == 0 {
-		return fmt.Errorf("team mit ID %d nicht gefunden", teamID)
+// DisqualifiedMatch ist der gesicherte Zustand eines Matches vor einer Disqualifikation
+type DisqualifiedMatch struct {
+	TeamID         int
+	MatchID        int
+	PrevScoreHome  sql.NullInt64
+	PrevScoreAway  sql.NullInt64
+	PrevReportedAt sql.NullTime
+	PrevReportedBy sql.NullString
+	CreatedAt      time.Time
+}
+
+// GetDisqualifiedMatches ruft alle durch eine Disqualifikation überschriebenen Matches eines Teams ab
+func (d *Database) GetDisqualifiedMatches(ctx context.Context, teamID int) ([]*DisqualifiedMatch, error) {
+	return getDisqualifiedMatches(d.with(ctx), teamID)
+}
+
+func getDisqualifiedMatches(q querier, teamID int) ([]*DisqualifiedMatch, error) {
+	matches, err := queryAll(q, scanDisqualifiedMatch,
+		`SELECT team_id, match_id, prev_score_home, prev_score_away, prev_reported_at, prev_reported_by, created_at
+		 FROM disqualified_matches WHERE team_id = ? ORDER BY match_id`,
+		teamID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der gesicherten Match-Zustände: %w", err)
 	}
+	return matches, nil
+}
 
-	return nil
+func scanDisqualifiedMatch(row rowScanner) (*DisqualifiedMatch, error) {
+	dm := &DisqualifiedMatch{}
+	err := row.Scan(
+		&dm.TeamID, &dm.MatchID, &dm.PrevScoreHome, &dm.PrevScoreAway,
+		&dm.PrevReportedAt, &dm.PrevReportedBy, &dm.CreatedAt,
+	)
+	return dm, err
 }
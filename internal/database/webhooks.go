@@ -0,0 +1,228 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Status einer Webhook-Zustellung
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+)
+
+// WebhookDelivery ist die Zustellung eines Events an einen Empfänger
+type WebhookDelivery struct {
+	ID            int
+	EventID       string
+	EventType     string
+	URL           string
+	Payload       string
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	CreatedAt     time.Time
+	DeliveredAt   sql.NullTime
+}
+
+// WebhookAttempt ist ein einzelner Zustellversuch im Protokoll
+type WebhookAttempt struct {
+	ID         int
+	DeliveryID int
+	Attempt    int
+	StatusCode sql.NullInt64
+	Error      sql.NullString
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// QueuedEvent ist ein serialisiertes Event mit den Empfängern, die es abonniert haben
+type QueuedEvent struct {
+	ID      string
+	Type    string
+	Payload []byte
+	URLs    []string
+}
+
+// WebhookRouter bereitet Liga-Events für die Warteschlange vor (siehe webhooks.Publisher)
+type WebhookRouter interface {
+	// Route serialisiert ein Event. Ohne Empfänger für den Event-Typ wird nil zurückgegeben.
+	Route(eventType string, data any) (*QueuedEvent, error)
+	// Notify wird nach dem Commit aufgerufen, sobald neue Zustellungen eingereiht sind
+	Notify()
+}
+
+// pendingEvent ist ein Event, das mit der nächsten Änderung eingereiht wird
+type pendingEvent struct {
+	eventType string
+	data      any
+}
+
+// SetWebhookRouter legt fest, wie Events aus WithEvent eingereiht werden (nil = Events werden verworfen)
+func (d *Database) SetWebhookRouter(router WebhookRouter) {
+	d.webhooks = router
+}
+
+// WithEvent gibt eine Kopie der Datenbank zurück, die das Event in derselben Transaktion wie
+// ihre Änderung in die Webhook-Warteschlange einreiht. Schlägt die Änderung fehl, wird auch
+// kein Event verschickt. Die Kopie ist für genau eine Änderung gedacht.
+func (d *Database) WithEvent(eventType string, data any) Store {
+	c := *d
+	c.events = append(append([]pendingEvent(nil), d.events...), pendingEvent{eventType: eventType, data: data})
+	return &c
+}
+
+// enqueueEvents reiht die Events aus WithEvent innerhalb von tx ein und meldet, ob Zustellungen angelegt wurden
+func (d *Database) enqueueEvents(tx *txn) (bool, error) {
+	if d.webhooks == nil {
+		return false, nil
+	}
+
+	queued := false
+	for _, pending := range d.events {
+		event, err := d.webhooks.Route(pending.eventType, pending.data)
+		if err != nil {
+			return false, err
+		}
+		if event == nil || len(event.URLs) == 0 {
+			continue
+		}
+		if err := d.enqueueWebhook(tx, event.ID, event.Type, event.Payload, event.URLs); err != nil {
+			return false, err
+		}
+		queued = true
+	}
+	return queued, nil
+}
+
+const webhookDeliveryColumns = `id, event_id, event_type, url, payload, status, attempts,
+		 next_attempt_at, last_error, created_at, delivered_at`
+
+func scanWebhookDelivery(row rowScanner) (*WebhookDelivery, error) {
+	delivery := &WebhookDelivery{}
+	err := row.Scan(
+		&delivery.ID, &delivery.EventID, &delivery.EventType, &delivery.URL, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError,
+		&delivery.CreatedAt, &delivery.DeliveredAt,
+	)
+	return delivery, err
+}
+
+func (d *Database) queryWebhookDeliveries(ctx context.Context, clause string, args ...any) ([]*WebhookDelivery, error) {
+	deliveries, err := queryAll(d.with(ctx), scanWebhookDelivery, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries "+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Webhook-Zustellungen: %w", err)
+	}
+	return deliveries, nil
+}
+
+// EnqueueWebhook legt für jeden Empfänger eine Zustellung des Events in der Warteschlange an
+func (d *Database) EnqueueWebhook(ctx context.Context, eventID, eventType string, payload []byte, urls []string) error {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := d.enqueueWebhook(tx, eventID, eventType, payload, urls); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("fehler beim Speichern der Transaktion: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) enqueueWebhook(q querier, eventID, eventType string, payload []byte, urls []string) error {
+	now := d.dialect.timeArg(time.Now())
+	for _, url := range urls {
+		_, err := q.Exec(
+			`INSERT INTO webhook_deliveries (event_id, event_type, url, payload, status, next_attempt_at)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			eventID, eventType, url, string(payload), WebhookPending, now,
+		)
+		if err != nil {
+			return fmt.Errorf("fehler beim Einreihen des Webhooks: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetDueWebhookDeliveries ruft offene Zustellungen ab, deren nächster Versuch fällig ist
+func (d *Database) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error) {
+	return d.queryWebhookDeliveries(ctx,
+		"WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?",
+		WebhookPending, d.dialect.timeArg(now), limit,
+	)
+}
+
+// GetWebhookDeliveries ruft die letzten Zustellungen ab (neueste zuerst)
+func (d *Database) GetWebhookDeliveries(ctx context.Context, limit int) ([]*WebhookDelivery, error) {
+	return d.queryWebhookDeliveries(ctx, "ORDER BY id DESC LIMIT ?", limit)
+}
+
+// RecordWebhookAttempt protokolliert einen Zustellversuch und aktualisiert die Zustellung.
+// status ist der neue Status, nextAttemptAt der Zeitpunkt des nächsten Versuchs (nur bei pending).
+func (d *Database) RecordWebhookAttempt(ctx context.Context, attempt *WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		 VALUES (?, ?, ?, ?, ?)`,
+		attempt.DeliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.Duration.Milliseconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("fehler beim Protokollieren des Zustellversuchs: %w", err)
+	}
+
+	var deliveredAt any
+	if status == WebhookDelivered {
+		deliveredAt = d.dialect.timeArg(time.Now())
+	}
+
+	_, err = tx.Exec(
+		`UPDATE webhook_deliveries
+		 SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, delivered_at = ?
+		 WHERE id = ?`,
+		status, attempt.Attempt, d.dialect.timeArg(nextAttemptAt), attempt.Error, deliveredAt, attempt.DeliveryID,
+	)
+	if err != nil {
+		return fmt.Errorf("fehler beim Aktualisieren der Webhook-Zustellung: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("fehler beim Speichern der Transaktion: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookAttempts ruft das Protokoll der Zustellversuche einer Zustellung ab
+func (d *Database) GetWebhookAttempts(ctx context.Context, deliveryID int) ([]*WebhookAttempt, error) {
+	attempts, err := queryAll(d.with(ctx), scanWebhookAttempt,
+		`SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		 FROM webhook_attempts WHERE delivery_id = ? ORDER BY attempt, id`,
+		deliveryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Abrufen der Zustellversuche: %w", err)
+	}
+	return attempts, nil
+}
+
+func scanWebhookAttempt(row rowScanner) (*WebhookAttempt, error) {
+	attempt := &WebhookAttempt{}
+	var durationMS int64
+	err := row.Scan(&attempt.ID, &attempt.DeliveryID, &attempt.Attempt, &attempt.StatusCode,
+		&attempt.Error, &durationMS, &attempt.CreatedAt)
+	attempt.Duration = time.Duration(durationMS) * time.Millisecond
+	return attempt, err
+}
@@ -0,0 +1,100 @@
+package discordtest
+
+import "github.com/bwmarrin/discordgo"
+
+const (
+	// GuildID ist die Guild, in der alle Test-Interactions stattfinden
+	GuildID = "guild-1"
+
+	// UserID ist der User, der alle Test-Interactions auslöst
+	UserID = "user-1"
+)
+
+// Command baut den Aufruf eines Slash Commands
+func Command(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return interaction(discordgo.InteractionApplicationCommand, discordgo.ApplicationCommandInteractionData{
+		Name:    name,
+		Options: options,
+	})
+}
+
+// ModalSubmit baut das Absenden eines Modals. Jeder Wert landet als TextInput in einer eigenen Zeile.
+func ModalSubmit(customID string, values ...string) *discordgo.InteractionCreate {
+	rows := make([]discordgo.MessageComponent, 0, len(values))
+	for _, value := range values {
+		rows = append(rows, &discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.TextInput{Value: value},
+			},
+		})
+	}
+
+	return interaction(discordgo.InteractionModalSubmit, discordgo.ModalSubmitInteractionData{
+		CustomID:   customID,
+		Components: rows,
+	})
+}
+
+// Button baut den Klick auf einen Button
+func Button(customID string) *discordgo.InteractionCreate {
+	return interaction(discordgo.InteractionMessageComponent, discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.ButtonComponent,
+	})
+}
+
+// InChannel setzt den Channel, in dem die Interaction ausgelöst wurde
+func InChannel(i *discordgo.InteractionCreate, channelID string) *discordgo.InteractionCreate {
+	i.ChannelID = channelID
+	return i
+}
+
+func interaction(t discordgo.InteractionType, data discordgo.InteractionData) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      "interaction-1",
+			Type:    t,
+			Data:    data,
+			GuildID: GuildID,
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: UserID, Username: "tester"},
+			},
+		},
+	}
+}
+
+// IntOption baut eine Integer-Option
+func IntOption(name string, value int) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionInteger,
+		Value: float64(value),
+	}
+}
+
+// StringOption baut eine String-Option
+func StringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionString,
+		Value: value,
+	}
+}
+
+// BoolOption baut eine Boolean-Option
+func BoolOption(name string, value bool) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionBoolean,
+		Value: value,
+	}
+}
+
+// RoleOption baut eine Rollen-Option
+func RoleOption(name, roleID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionRole,
+		Value: roleID,
+	}
+}
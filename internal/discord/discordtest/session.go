@@ -0,0 +1,326 @@
+// Package discordtest stellt eine aufzeichnende discord.Session für Tests bereit.
+// Alle Aufrufe laufen gegen einen Speicher statt gegen die Discord-API.
+package discordtest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/discord"
+)
+
+// Message ist eine gesendete oder bearbeitete Nachricht
+type Message struct {
+	ID        string
+	ChannelID string
+	Content   string
+	Embeds    []*discordgo.MessageEmbed
+	Files     []*discordgo.File
+	Pinned    bool
+}
+
+// Session zeichnet alle Discord-Aufrufe auf. Der Zero-Value ist nicht nutzbar, siehe New.
+type Session struct {
+	mu     sync.Mutex
+	nextID int
+	errs   map[string]error
+
+	// Calls enthält die Namen aller aufgerufenen Methoden in Reihenfolge
+	Calls []string
+
+	Responses []*discordgo.InteractionResponse
+	Edits     []*discordgo.WebhookEdit
+	Followups []*discordgo.WebhookParams
+
+	// Channels enthält alle erstellten und noch nicht gelöschten Channels
+	Channels map[string]*discordgo.Channel
+	Deleted  []string
+
+	Messages []*Message
+}
+
+var _ discord.Session = (*Session)(nil)
+
+// New erstellt eine leere Session
+func New() *Session {
+	return &Session{
+		errs:     make(map[string]error),
+		Channels: make(map[string]*discordgo.Channel),
+	}
+}
+
+// FailOn lässt alle weiteren Aufrufe der Methode mit err fehlschlagen (nil = wieder erfolgreich)
+func (s *Session) FailOn(method string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		delete(s.errs, method)
+		return
+	}
+	s.errs[method] = err
+}
+
+// Count gibt zurück, wie oft eine Methode aufgerufen wurde
+func (s *Session) Count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, call := range s.Calls {
+		if call == method {
+			count++
+		}
+	}
+	return count
+}
+
+// LastResponse gibt die letzte Interaction-Antwort zurück (nil = keine)
+func (s *Session) LastResponse() *discordgo.InteractionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.Responses) == 0 {
+		return nil
+	}
+	return s.Responses[len(s.Responses)-1]
+}
+
+// LastEdit gibt die letzte Bearbeitung einer Interaction-Antwort zurück (nil = keine)
+func (s *Session) LastEdit() *discordgo.WebhookEdit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.Edits) == 0 {
+		return nil
+	}
+	return s.Edits[len(s.Edits)-1]
+}
+
+// MessagesIn gibt alle Nachrichten eines Channels zurück
+func (s *Session) MessagesIn(channelID string) []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []*Message
+	for _, msg := range s.Messages {
+		if msg.ChannelID == channelID {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// call zeichnet einen Aufruf auf und gibt den hinterlegten Fehler zurück. Muss mit gesperrtem mu aufgerufen werden.
+func (s *Session) call(method string) error {
+	s.Calls = append(s.Calls, method)
+	return s.errs[method]
+}
+
+func (s *Session) id(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+func (s *Session) channel(channelID string) (*discordgo.Channel, error) {
+	channel, ok := s.Channels[channelID]
+	if !ok {
+		return nil, fmt.Errorf("unbekannter channel %s", channelID)
+	}
+	return channel, nil
+}
+
+func (s *Session) send(channelID string, msg *Message) (*discordgo.Message, error) {
+	if _, err := s.channel(channelID); err != nil {
+		return nil, err
+	}
+	msg.ID = s.id("message")
+	msg.ChannelID = channelID
+	s.Messages = append(s.Messages, msg)
+	return &discordgo.Message{ID: msg.ID, ChannelID: channelID, Content: msg.Content, Embeds: msg.Embeds}, nil
+}
+
+// AddChannel legt einen bereits bestehenden Channel an, z.B. einen Match- oder Tabellen-Channel
+func (s *Session) AddChannel(channelID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Channels[channelID] = &discordgo.Channel{ID: channelID, Name: name, Type: discordgo.ChannelTypeGuildText}
+}
+
+func (s *Session) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("InteractionRespond"); err != nil {
+		return err
+	}
+	s.Responses = append(s.Responses, resp)
+	return nil
+}
+
+func (s *Session) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("InteractionResponseEdit"); err != nil {
+		return nil, err
+	}
+	s.Edits = append(s.Edits, newresp)
+	return &discordgo.Message{ID: s.id("message"), ChannelID: interaction.ChannelID}, nil
+}
+
+func (s *Session) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("FollowupMessageCreate"); err != nil {
+		return nil, err
+	}
+	s.Followups = append(s.Followups, data)
+	return &discordgo.Message{ID: s.id("message"), ChannelID: interaction.ChannelID, Content: data.Content}, nil
+}
+
+func (s *Session) GuildChannelCreateComplex(guildID string, data discordgo.GuildChannelCreateData, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("GuildChannelCreateComplex"); err != nil {
+		return nil, err
+	}
+	channel := &discordgo.Channel{
+		ID:                   s.id("channel"),
+		GuildID:              guildID,
+		Name:                 data.Name,
+		Type:                 data.Type,
+		ParentID:             data.ParentID,
+		PermissionOverwrites: data.PermissionOverwrites,
+	}
+	s.Channels[channel.ID] = channel
+	return channel, nil
+}
+
+func (s *Session) ChannelEdit(channelID string, data *discordgo.ChannelEdit, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelEdit"); err != nil {
+		return nil, err
+	}
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if data.Name != "" {
+		channel.Name = data.Name
+	}
+	return channel, nil
+}
+
+func (s *Session) ChannelDelete(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelDelete"); err != nil {
+		return nil, err
+	}
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.Channels, channelID)
+	s.Deleted = append(s.Deleted, channelID)
+	return channel, nil
+}
+
+func (s *Session) ChannelPermissionSet(channelID, targetID string, targetType discordgo.PermissionOverwriteType, allow, deny int64, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelPermissionSet"); err != nil {
+		return err
+	}
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return err
+	}
+	for _, overwrite := range channel.PermissionOverwrites {
+		if overwrite.ID == targetID {
+			overwrite.Type, overwrite.Allow, overwrite.Deny = targetType, allow, deny
+			return nil
+		}
+	}
+	channel.PermissionOverwrites = append(channel.PermissionOverwrites, &discordgo.PermissionOverwrite{
+		ID: targetID, Type: targetType, Allow: allow, Deny: deny,
+	})
+	return nil
+}
+
+func (s *Session) ChannelPermissionDelete(channelID, targetID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelPermissionDelete"); err != nil {
+		return err
+	}
+	channel, err := s.channel(channelID)
+	if err != nil {
+		return err
+	}
+	kept := channel.PermissionOverwrites[:0]
+	for _, overwrite := range channel.PermissionOverwrites {
+		if overwrite.ID != targetID {
+			kept = append(kept, overwrite)
+		}
+	}
+	channel.PermissionOverwrites = kept
+	return nil
+}
+
+func (s *Session) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelMessageSend"); err != nil {
+		return nil, err
+	}
+	return s.send(channelID, &Message{Content: content})
+}
+
+func (s *Session) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelMessageSendEmbed"); err != nil {
+		return nil, err
+	}
+	return s.send(channelID, &Message{Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+func (s *Session) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelMessageSendComplex"); err != nil {
+		return nil, err
+	}
+	embeds := data.Embeds
+	if data.Embed != nil {
+		embeds = append(embeds, data.Embed)
+	}
+	return s.send(channelID, &Message{Content: data.Content, Embeds: embeds, Files: data.Files})
+}
+
+func (s *Session) ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelMessageEditEmbed"); err != nil {
+		return nil, err
+	}
+	for _, msg := range s.Messages {
+		if msg.ChannelID == channelID && msg.ID == messageID {
+			msg.Embeds = []*discordgo.MessageEmbed{embed}
+			return &discordgo.Message{ID: msg.ID, ChannelID: channelID, Embeds: msg.Embeds}, nil
+		}
+	}
+	return nil, fmt.Errorf("unbekannte nachricht %s in channel %s", messageID, channelID)
+}
+
+func (s *Session) ChannelMessagePin(channelID, messageID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.call("ChannelMessagePin"); err != nil {
+		return err
+	}
+	for _, msg := range s.Messages {
+		if msg.ChannelID == channelID && msg.ID == messageID {
+			msg.Pinned = true
+			return nil
+		}
+	}
+	return fmt.Errorf("unbekannte nachricht %s in channel %s", messageID, channelID)
+}
@@ -0,0 +1,88 @@
+package discord
+
+import (
+	"log"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Responder merkt sich, wie eine Interaction bereits beantwortet wurde, damit eine
+// Fehlermeldung danach auf dem passenden Weg ankommt. Der Router gibt ihn statt der
+// Session an die Handler weiter.
+type Responder struct {
+	Session
+
+	mu       sync.Mutex
+	answered bool
+	deferred bool
+}
+
+// NewResponder umschließt die Session für eine einzelne Interaction
+func NewResponder(s Session) *Responder {
+	return &Responder{Session: s}
+}
+
+func (r *Responder) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	if err := r.Session.InteractionRespond(interaction, resp, options...); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.answered = true
+	r.deferred = resp.Type == discordgo.InteractionResponseDeferredChannelMessageWithSource
+	return nil
+}
+
+// ReplyError antwortet einheitlich mit einer ephemeralen Fehlermeldung:
+//   - noch keine Antwort: als Antwort auf die Interaction
+//   - zurückgestellte Antwort: die "denkt nach"-Nachricht wird durch die Meldung ersetzt
+//   - bereits beantwortet (z.B. vor einer Panic): als Follow-up
+//
+// Autocomplete-Anfragen erhalten stattdessen eine leere Vorschlagsliste.
+func (r *Responder) ReplyError(i *discordgo.InteractionCreate, message string) {
+	if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		r.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{
+				Choices: []*discordgo.ApplicationCommandOptionChoice{},
+			},
+		})
+		return
+	}
+
+	r.mu.Lock()
+	answered, deferred := r.answered, r.deferred
+	r.mu.Unlock()
+
+	content := "❌ " + message
+	var err error
+	switch {
+	case deferred:
+		_, err = r.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &content,
+		})
+	case !answered:
+		err = r.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		if err == nil {
+			return
+		}
+		// Die Interaction kann auch außerhalb des Responders beantwortet worden sein
+		fallthrough
+	default:
+		_, err = r.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+	if err != nil {
+		log.Printf("[Discord] Fehlermeldung konnte nicht gesendet werden: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package permissions
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+)
+
+// Tier ist eine Staff-Stufe der Liga
+type Tier string
+
+const (
+	TierAdmin   Tier = "admin"
+	TierReferee Tier = "referee"
+	TierCaster  Tier = "caster"
+	TierCaptain Tier = "captain"
+)
+
+// Policy entscheidet anhand der Discord-Rollen, wer welchen Command ausführen darf
+type Policy struct {
+	superUsers map[string]bool
+	roleTiers  map[string][]Tier
+	commands   map[string][]Tier
+}
+
+// NewPolicy erstellt eine Policy aus der Konfiguration
+func NewPolicy(cfg *config.Config) *Policy {
+	p := &Policy{
+		superUsers: make(map[string]bool),
+		roleTiers:  make(map[string][]Tier),
+		commands:   make(map[string][]Tier),
+	}
+
+	for _, userID := range cfg.Permissions.SuperUsers {
+		p.superUsers[userID] = true
+	}
+
+	p.addRoles(TierAdmin, cfg.Roles.Admin)
+	p.addRoles(TierReferee, cfg.Roles.Referee)
+	p.addRoles(TierCaster, cfg.Roles.Caster)
+	p.addRoles(TierCaptain, cfg.Roles.Captain)
+
+	for command, tiers := range cfg.Permissions.Commands {
+		for _, tier := range tiers {
+			p.commands[command] = append(p.commands[command], Tier(tier))
+		}
+	}
+
+	return p
+}
+
+func (p *Policy) addRoles(tier Tier, roleIDs []string) {
+	for _, roleID := range roleIDs {
+		if roleID != "" {
+			p.roleTiers[roleID] = append(p.roleTiers[roleID], tier)
+		}
+	}
+}
+
+// Restricted gibt zurück, ob ein Command überhaupt Berechtigungen erfordert
+func (p *Policy) Restricted(command string) bool {
+	return len(p.commands[command]) > 0
+}
+
+// Tiers gibt alle Stufen zurück, die ein Member besitzt.
+// Discord-Administratoren und Super-User gelten immer als Admin.
+func (p *Policy) Tiers(member *discordgo.Member) []Tier {
+	if member == nil {
+		return nil
+	}
+
+	var tiers []Tier
+	if (member.User != nil && p.superUsers[member.User.ID]) || member.Permissions&discordgo.PermissionAdministrator != 0 {
+		tiers = append(tiers, TierAdmin)
+	}
+
+	for _, roleID := range member.Roles {
+		tiers = append(tiers, p.roleTiers[roleID]...)
+	}
+
+	return tiers
+}
+
+// HasTier prüft ob der Member die angegebene Stufe besitzt
+func (p *Policy) HasTier(member *discordgo.Member, tier Tier) bool {
+	for _, t := range p.Tiers(member) {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed prüft ob der Member den Command ausführen darf.
+// Admins dürfen alle Commands ausführen, Commands ohne Eintrag sind frei.
+func (p *Policy) Allowed(member *discordgo.Member, command string) bool {
+	allowed := p.commands[command]
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, tier := range p.Tiers(member) {
+		if tier == TierAdmin {
+			return true
+		}
+		for _, a := range allowed {
+			if tier == a {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// DefaultMemberPermissions liefert die Discord-seitige Sichtbarkeit für die Command-Registrierung.
+// Reine Admin-Commands werden nur Administratoren angezeigt, alle anderen sind sichtbar
+// und werden vom Bot selbst geprüft.
+func (p *Policy) DefaultMemberPermissions(command string) *int64 {
+	allowed := p.commands[command]
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, tier := range allowed {
+		if tier != TierAdmin {
+			return nil
+		}
+	}
+
+	perm := int64(discordgo.PermissionAdministrator)
+	return &perm
+}
@@ -0,0 +1,170 @@
+package permissions
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+)
+
+const superUser = "423480294948208661"
+
+func testPolicy() *Policy {
+	cfg := config.Default()
+	cfg.Roles = config.RolesConfig{
+		Admin:   []string{"role-admin"},
+		Referee: []string{"role-referee"},
+		Caster:  []string{"role-caster", "role-staff"},
+		Captain: []string{"role-captain", "role-staff"},
+	}
+	return NewPolicy(cfg)
+}
+
+func member(userID string, roles ...string) *discordgo.Member {
+	return &discordgo.Member{User: &discordgo.User{ID: userID}, Roles: roles}
+}
+
+func TestTiers(t *testing.T) {
+	p := testPolicy()
+	discordAdmin := member("user-1")
+	discordAdmin.Permissions = discordgo.PermissionAdministrator
+
+	tests := []struct {
+		name   string
+		member *discordgo.Member
+		want   []Tier
+	}{
+		{"ohne Member", nil, nil},
+		{"ohne Rollen", member("user-1"), nil},
+		{"Super-User", member(superUser), []Tier{TierAdmin}},
+		{"Discord-Administrator", discordAdmin, []Tier{TierAdmin}},
+		{"Admin", member("user-1", "role-admin"), []Tier{TierAdmin}},
+		{"Referee", member("user-1", "role-referee"), []Tier{TierReferee}},
+		{"Caster", member("user-1", "role-caster"), []Tier{TierCaster}},
+		{"Captain", member("user-1", "role-captain"), []Tier{TierCaptain}},
+		{"Rolle mit zwei Stufen", member("user-1", "role-staff"), []Tier{TierCaster, TierCaptain}},
+		{"unbekannte Rolle", member("user-1", "role-unknown"), nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Tiers(tt.member); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tiers = %v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	p := testPolicy()
+
+	tests := []struct {
+		name    string
+		member  *discordgo.Member
+		allowed []string
+		denied  []string
+	}{
+		{
+			name:    "Super-User",
+			member:  member(superUser),
+			allowed: []string{"schedule", "backup", "report_result", "standings"},
+		},
+		{
+			name:    "Admin",
+			member:  member("user-1", "role-admin"),
+			allowed: []string{"schedule", "set_result", "match_time", "standings"},
+		},
+		{
+			name:    "Referee",
+			member:  member("user-1", "role-referee"),
+			allowed: []string{"createchannels", "audit", "report_result", "match_time", "standings"},
+			denied:  []string{"schedule", "set_result", "backup"},
+		},
+		{
+			name:    "Caster",
+			member:  member("user-1", "role-caster"),
+			allowed: []string{"match_time", "matches", "standings"},
+			denied:  []string{"report_result", "createchannels", "audit"},
+		},
+		{
+			name:    "Captain",
+			member:  member("user-1", "role-captain"),
+			allowed: []string{"report_result", "match_time", "team"},
+			denied:  []string{"schedule", "createchannels", "withdraw"},
+		},
+		{
+			name:    "ohne Rollen",
+			member:  member("user-1"),
+			allowed: []string{"standings", "matches", "team"},
+			denied:  []string{"report_result", "match_time", "schedule"},
+		},
+		{
+			name:    "ohne Member",
+			allowed: []string{"standings"},
+			denied:  []string{"report_result", "schedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			for _, command := range tt.allowed {
+				if !p.Allowed(tt.member, command) {
+					t.Errorf("/%s verweigert, erwartet erlaubt", command)
+				}
+			}
+			for _, command := range tt.denied {
+				if p.Allowed(tt.member, command) {
+					t.Errorf("/%s erlaubt, erwartet verweigert", command)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultCommandTable(t *testing.T) {
+	cfg := config.Default()
+	p := NewPolicy(cfg)
+
+	// Jede Stufe muss mindestens einen Command freischalten, sonst ist ihre Rolle wirkungslos
+	used := make(map[Tier]bool)
+	for command, tiers := range cfg.Permissions.Commands {
+		for _, tier := range tiers {
+			switch Tier(tier) {
+			case TierAdmin, TierReferee, TierCaster, TierCaptain:
+				used[Tier(tier)] = true
+			default:
+				t.Errorf("/%s: unbekannte Stufe %q", command, tier)
+			}
+		}
+	}
+	for _, tier := range []Tier{TierAdmin, TierReferee, TierCaster, TierCaptain} {
+		if !used[tier] {
+			t.Errorf("Stufe %q schaltet keinen Command frei", tier)
+		}
+	}
+
+	// Reine Admin-Commands sieht in Discord nur die Administration, alle anderen prüft der Bot selbst
+	adminOnly := int64(discordgo.PermissionAdministrator)
+	tests := map[string]*int64{
+		"schedule":       &adminOnly,
+		"backup":         &adminOnly,
+		"set_result":     &adminOnly,
+		"createchannels": nil,
+		"audit":          nil,
+		"report_result":  nil,
+		"match_time":     nil,
+		"standings":      nil,
+	}
+	for command, want := range tests {
+		got := p.DefaultMemberPermissions(command)
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Errorf("DefaultMemberPermissions(%s) = %v, erwartet %v", command, got, want)
+		}
+		if restricted := p.Restricted(command); restricted != (cfg.Permissions.Commands[command] != nil) {
+			t.Errorf("Restricted(%s) = %v", command, restricted)
+		}
+	}
+}
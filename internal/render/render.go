@@ -0,0 +1,253 @@
+// Package render zeichnet Tabellen und Spieltag-Ergebnisse als PNG-Grafiken
+// für Discord und Social Media. Hintergrund und Logo stammen aus web/static.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// Width ist die Breite aller Grafiken in Pixeln
+	Width = 1200
+
+	// maxBackgroundWidth begrenzt die Größe des im Speicher gehaltenen Hintergrunds
+	maxBackgroundWidth = 1600
+
+	backgroundFile = "bg/bg.png"
+	logoFile       = "logo-bg.png"
+)
+
+// Farben der Grafiken
+var (
+	colorText      = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	colorMuted     = color.RGBA{0xA8, 0xAD, 0xB8, 0xFF}
+	colorAccent    = color.RGBA{0xF5, 0xC5, 0x42, 0xFF}
+	colorOverlay   = color.RGBA{0x0B, 0x0D, 0x14, 0xC8}
+	colorRowBand   = color.RGBA{0xFF, 0xFF, 0xFF, 0x12}
+	colorHeaderBar = color.RGBA{0xFF, 0xFF, 0xFF, 0x24}
+	colorFallback  = color.RGBA{0x1E, 0x21, 0x2B, 0xFF}
+)
+
+// Renderer zeichnet die Grafiken. Er ist sicher für die gleichzeitige Verwendung.
+type Renderer struct {
+	// LeagueName erscheint in der Fußzeile jeder Grafik
+	LeagueName string
+
+	background image.Image
+	logo       image.Image
+	regular    *opentype.Font
+	bold       *opentype.Font
+}
+
+// New lädt Hintergrund und Logo aus dem Asset-Verzeichnis (web/static).
+// Ohne Verzeichnis werden die Grafiken mit einfarbigem Hintergrund gezeichnet.
+func New(assetsDir string) (*Renderer, error) {
+	r := &Renderer{}
+
+	var err error
+	if r.regular, err = opentype.Parse(goregular.TTF); err != nil {
+		return nil, fmt.Errorf("fehler beim Laden der Schriftart: %w", err)
+	}
+	if r.bold, err = opentype.Parse(gobold.TTF); err != nil {
+		return nil, fmt.Errorf("fehler beim Laden der Schriftart: %w", err)
+	}
+
+	if assetsDir == "" {
+		return r, nil
+	}
+
+	background, err := loadPNG(filepath.Join(assetsDir, backgroundFile))
+	if err != nil {
+		return nil, err
+	}
+	r.background = shrink(background, maxBackgroundWidth)
+
+	if r.logo, err = loadPNG(filepath.Join(assetsDir, logoFile)); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Öffnen von %s: %w", path, err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Lesen von %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// shrink verkleinert ein Bild proportional auf die maximale Breite
+func shrink(img image.Image, maxWidth int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxWidth {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, maxWidth, b.Dy()*maxWidth/b.Dx()))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// canvas ist eine Grafik im Aufbau mit eigenen Schrift-Instanzen
+type canvas struct {
+	*image.RGBA
+	r     *Renderer
+	faces map[faceKey]font.Face
+}
+
+type faceKey struct {
+	bold bool
+	size float64
+}
+
+// newCanvas erstellt eine Grafik mit abgedunkeltem Hintergrund und Logo
+func (r *Renderer) newCanvas(height int) *canvas {
+	c := &canvas{
+		RGBA:  image.NewRGBA(image.Rect(0, 0, Width, height)),
+		r:     r,
+		faces: make(map[faceKey]font.Face),
+	}
+
+	if r.background != nil {
+		// Hintergrund auf die Grafik skalieren und mittig zuschneiden
+		src := r.background.Bounds()
+		scaledW, scaledH := Width, src.Dy()*Width/src.Dx()
+		if scaledH < height {
+			scaledW, scaledH = src.Dx()*height/src.Dy(), height
+		}
+		x, y := (Width-scaledW)/2, (height-scaledH)/2
+		draw.ApproxBiLinear.Scale(c.RGBA, image.Rect(x, y, x+scaledW, y+scaledH), r.background, src, draw.Src, nil)
+	} else {
+		draw.Draw(c.RGBA, c.Bounds(), image.NewUniform(colorFallback), image.Point{}, draw.Src)
+	}
+	c.fill(c.Bounds(), colorOverlay)
+
+	if r.logo != nil {
+		draw.CatmullRom.Scale(c.RGBA, image.Rect(40, 30, 170, 160), r.logo, r.logo.Bounds(), draw.Over, nil)
+	}
+
+	return c
+}
+
+// header zeichnet Titel und Untertitel neben dem Logo
+func (c *canvas) header(title, subtitle string) {
+	x := 40
+	if c.r.logo != nil {
+		x = 200
+	}
+	c.text(title, x, 95, 54, true, colorText, alignLeft, Width-x-40)
+	c.text(subtitle, x, 140, 28, false, colorAccent, alignLeft, Width-x-40)
+}
+
+// footer zeichnet den Liga-Namen und einen Hinweis am unteren Rand
+func (c *canvas) footer(note string) {
+	y := c.Bounds().Dy() - 28
+	if c.r.LeagueName != "" {
+		c.text(c.r.LeagueName, 40, y, 22, true, colorMuted, alignLeft, Width/2)
+	}
+	c.text(note, Width-40, y, 22, false, colorMuted, alignRight, Width/2)
+}
+
+// fill übermalt ein Rechteck mit einer (halbtransparenten) Farbe
+func (c *canvas) fill(rect image.Rectangle, col color.Color) {
+	draw.Draw(c.RGBA, rect, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+// text zeichnet einen Text an der Grundlinie y. Zu lange Texte werden mit "…" auf maxWidth gekürzt.
+func (c *canvas) text(s string, x, y int, size float64, bold bool, col color.Color, a align, maxWidth int) {
+	face := c.face(size, bold)
+	s = fit(face, s, maxWidth)
+
+	width := font.MeasureString(face, s).Round()
+	switch a {
+	case alignCenter:
+		x -= width / 2
+	case alignRight:
+		x -= width
+	}
+
+	d := &font.Drawer{
+		Dst:  c.RGBA,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// face gibt eine Schrift-Instanz zurück. Instanzen sind nicht threadsicher und gehören deshalb zur Grafik.
+func (c *canvas) face(size float64, bold bool) font.Face {
+	key := faceKey{bold: bold, size: size}
+	if face, ok := c.faces[key]; ok {
+		return face
+	}
+
+	f := c.r.regular
+	if bold {
+		f = c.r.bold
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		// Kann bei den eingebetteten Go-Schriften nicht auftreten, trotzdem lesbar bleiben
+		face = basicfont.Face7x13
+	}
+	c.faces[key] = face
+	return face
+}
+
+// encode schließt die Schriften und kodiert die Grafik als PNG
+func (c *canvas) encode() ([]byte, error) {
+	for _, face := range c.faces {
+		face.Close()
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.RGBA); err != nil {
+		return nil, fmt.Errorf("fehler beim Kodieren der Grafik: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fit kürzt einen Text, bis er in die angegebene Breite passt
+func fit(face font.Face, s string, maxWidth int) string {
+	if maxWidth <= 0 || font.MeasureString(face, s).Round() <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if font.MeasureString(face, candidate).Round() <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
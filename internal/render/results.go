@@ -0,0 +1,77 @@
+package render
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+)
+
+const resultsRowHeight = 86
+
+// MatchdayResults zeichnet die Ergebnisse eines Spieltags als Karte.
+// Noch nicht gespielte Matches erscheinen als Paarung, Freilose als "spielfrei".
+func (r *Renderer) MatchdayResults(division, matchday int, matches []*database.Match, teamNames map[int]string) ([]byte, error) {
+	height := standingsTop + resultsRowHeight*len(matches) + footerHeight + 20
+	c := r.newCanvas(height)
+	c.header(fmt.Sprintf("Division %d – Woche %d", division, matchday), "Ergebnisse / Results")
+
+	const (
+		center    = Width / 2
+		nameWidth = center - 120 - 50
+	)
+
+	played := 0
+	y := standingsTop + 10
+	for i, match := range matches {
+		if i%2 == 1 {
+			c.fill(image.Rect(30, y, Width-30, y+resultsRowHeight), colorRowBand)
+		}
+		baseline := y + resultsRowHeight/2 + 12
+
+		if match.IsBye() {
+			team := match.TeamHomeID
+			if team == 0 {
+				team = int(match.TeamAwayID.Int64)
+			}
+			c.text(teamNames[team]+" – spielfrei / bye", center, baseline, 30, false, colorMuted, alignCenter, Width-120)
+			y += resultsRowHeight
+			continue
+		}
+
+		home, away := teamNames[match.TeamHomeID], teamNames[int(match.TeamAwayID.Int64)]
+		homeColor, awayColor := colorText, colorText
+		homeBold, awayBold := false, false
+		score := "vs"
+
+		if match.IsPlayed() {
+			played++
+			scoreHome, scoreAway := match.ScoreHome.Int64, match.ScoreAway.Int64
+			score = fmt.Sprintf("%d : %d", scoreHome, scoreAway)
+			if scoreHome > scoreAway {
+				homeBold, awayColor = true, colorMuted
+			} else {
+				awayBold, homeColor = true, colorMuted
+			}
+		}
+
+		c.text(home, center-120, baseline, 34, homeBold, homeColor, alignRight, nameWidth)
+		c.text(score, center, baseline, 40, true, colorAccent, alignCenter, 0)
+		c.text(away, center+120, baseline, 34, awayBold, awayColor, alignLeft, nameWidth)
+		y += resultsRowHeight
+	}
+
+	c.footer(fmt.Sprintf("%d von %d Matches gespielt", played, countMatches(matches)))
+	return c.encode()
+}
+
+// countMatches zählt die Matches ohne Freilose
+func countMatches(matches []*database.Match) int {
+	count := 0
+	for _, match := range matches {
+		if !match.IsBye() {
+			count++
+		}
+	}
+	return count
+}
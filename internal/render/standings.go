@@ -0,0 +1,85 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"time"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/standings"
+)
+
+const (
+	standingsTop       = 190
+	standingsRowHeight = 58
+	footerHeight       = 70
+)
+
+// standingsColumn ist eine rechtsbündige Zahlenspalte der Tabelle
+type standingsColumn struct {
+	title string
+	x     int
+	value func(row *standings.Row) string
+}
+
+var standingsColumns = []standingsColumn{
+	{"Sp", 720, func(row *standings.Row) string { return strconv.Itoa(row.Played) }},
+	{"S", 800, func(row *standings.Row) string { return strconv.Itoa(row.Wins) }},
+	{"N", 880, func(row *standings.Row) string { return strconv.Itoa(row.Losses) }},
+	{"Spiele", 1010, func(row *standings.Row) string { return fmt.Sprintf("%d:%d", row.GamesWon, row.GamesLost) }},
+	{"Diff", 1090, func(row *standings.Row) string { return fmt.Sprintf("%+d", row.GameDiff()) }},
+	{"Pkt", 1160, func(row *standings.Row) string { return strconv.Itoa(row.Points) }},
+}
+
+// Standings zeichnet die Tabelle einer Division
+func (r *Renderer) Standings(division int, rows []*standings.Row, stamp time.Time) ([]byte, error) {
+	height := standingsTop + standingsRowHeight*(len(rows)+1) + footerHeight
+	c := r.newCanvas(height)
+
+	subtitle := "Tabelle / Standings"
+	if !stamp.IsZero() {
+		subtitle += " • Stand " + stamp.Format("02.01.2006")
+	}
+	c.header(fmt.Sprintf("Division %d", division), subtitle)
+
+	// Spaltenköpfe
+	y := standingsTop
+	c.fill(image.Rect(30, y, Width-30, y+standingsRowHeight), colorHeaderBar)
+	baseline := y + standingsRowHeight/2 + 9
+	c.text("#", 70, baseline, 24, true, colorMuted, alignCenter, 0)
+	c.text("Team", 110, baseline, 24, true, colorMuted, alignLeft, 0)
+	for _, column := range standingsColumns {
+		c.text(column.title, column.x, baseline, 24, true, colorMuted, alignRight, 0)
+	}
+
+	for i, row := range rows {
+		y += standingsRowHeight
+		if i%2 == 1 {
+			c.fill(image.Rect(30, y, Width-30, y+standingsRowHeight), colorRowBand)
+		}
+		baseline := y + standingsRowHeight/2 + 10
+
+		name, nameColor := row.Name, colorText
+		switch {
+		case row.IsDisqualified:
+			name, nameColor = name+" (DQ)", colorMuted
+		case row.IsWithdrawn:
+			name, nameColor = name+" (zg.)", colorMuted
+		}
+
+		positionColor := colorText
+		if row.Position == 1 {
+			positionColor = colorAccent
+		}
+
+		c.text(strconv.Itoa(row.Position), 70, baseline, 28, true, positionColor, alignCenter, 0)
+		c.text(name, 110, baseline, 28, row.Position == 1, nameColor, alignLeft, standingsColumns[0].x-110-60)
+		for _, column := range standingsColumns {
+			bold := column.title == "Pkt"
+			c.text(column.value(row), column.x, baseline, 28, bold, nameColor, alignRight, 0)
+		}
+	}
+
+	c.footer(fmt.Sprintf("%d Punkte pro Sieg • DQ = disqualifiziert • zg. = zurückgezogen", standings.PointsPerWin))
+	return c.encode()
+}
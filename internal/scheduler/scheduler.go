@@ -66,6 +66,11 @@ func GenerateMatches(teamIDs []int) ([][]Match, error) {
 			homeTeamID := teamIDs[homeIdx]
 			awayTeamID := teamIDs[awayIdx]
 
+			// Beim Free Win spielt das echte Team immer zu Hause, die Auswärtsseite bleibt leer
+			if homeTeamID == 0 {
+				homeTeamID, awayTeamID = awayTeamID, 0
+			}
+
 			matches = append(matches, Match{
 				Matchday:   matchdayNum + 1,
 				TeamHomeID: homeTeamID,
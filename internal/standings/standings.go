@@ -0,0 +1,116 @@
+package standings
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+)
+
+// PointsPerWin sind die Punkte für einen Sieg
+const PointsPerWin = 3
+
+// Row ist eine Zeile der Tabelle einer Division
+type Row struct {
+	Position       int
+	TeamID         int
+	Name           string
+	IsDisqualified bool
+	IsWithdrawn    bool
+	Played         int
+	Wins           int
+	Losses         int
+	GamesWon       int
+	GamesLost      int
+	Points         int
+}
+
+// GameDiff gibt die Differenz aus gewonnenen und verlorenen Spielen zurück
+func (r *Row) GameDiff() int {
+	return r.GamesWon - r.GamesLost
+}
+
+// Calculate berechnet die Tabelle aus Teams und Matches einer Division.
+// Free Wins zählen nicht. Sortiert wird nach Punkten, Spieldifferenz und gewonnenen Spielen.
+func Calculate(teams []*database.Team, matches []*database.Match) []*Row {
+	rows := make([]*Row, 0, len(teams))
+	byID := make(map[int]*Row, len(teams))
+	for _, team := range teams {
+		row := &Row{
+			TeamID:         team.ID,
+			Name:           team.Name,
+			IsDisqualified: team.IsDisqualified,
+			IsWithdrawn:    team.IsWithdrawn,
+		}
+		rows = append(rows, row)
+		byID[team.ID] = row
+	}
+
+	// Vorsortierung nach Namen, damit Gleichstände stabil bleiben
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].Name < rows[b].Name
+	})
+
+	for _, match := range matches {
+		if match.IsBye() || !match.IsPlayed() {
+			continue
+		}
+
+		home := byID[match.TeamHomeID]
+		away := byID[int(match.TeamAwayID.Int64)]
+		if home == nil || away == nil {
+			continue
+		}
+
+		scoreHome := int(match.ScoreHome.Int64)
+		scoreAway := int(match.ScoreAway.Int64)
+
+		home.Played++
+		away.Played++
+		home.GamesWon += scoreHome
+		home.GamesLost += scoreAway
+		away.GamesWon += scoreAway
+		away.GamesLost += scoreHome
+
+		if scoreHome > scoreAway {
+			home.Wins++
+			home.Points += PointsPerWin
+			away.Losses++
+		} else {
+			away.Wins++
+			away.Points += PointsPerWin
+			home.Losses++
+		}
+	}
+
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].Points != rows[b].Points {
+			return rows[a].Points > rows[b].Points
+		}
+		if rows[a].GameDiff() != rows[b].GameDiff() {
+			return rows[a].GameDiff() > rows[b].GameDiff()
+		}
+		return rows[a].GamesWon > rows[b].GamesWon
+	})
+
+	for i, row := range rows {
+		row.Position = i + 1
+	}
+
+	return rows
+}
+
+// ForDivision lädt Teams und Matches einer Division und berechnet die Tabelle
+func ForDivision(ctx context.Context, db database.Repository, division int) ([]*Row, error) {
+	teams, err := db.GetTeamsByDivision(ctx, division)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := db.GetMatchesByDivision(ctx, division)
+	if err != nil {
+		return nil, err
+	}
+
+	return Calculate(teams, matches), nil
+}
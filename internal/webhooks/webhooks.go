@@ -0,0 +1,253 @@
+// Package webhooks verschickt Liga-Events als signierte JSON-Webhooks.
+// Events werden zuerst in der SQLite-Warteschlange gespeichert und dann von
+// Run zugestellt, sodass sie auch einen Neustart des Bots überstehen.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+const (
+	defaultMaxAttempts  = 8
+	defaultBaseDelay    = 30 * time.Second
+	defaultMaxDelay     = time.Hour
+	defaultPollInterval = 5 * time.Second
+	defaultBatchSize    = 50
+	requestTimeout      = 10 * time.Second
+)
+
+// Publisher reiht Events ein und stellt sie an die konfigurierten Empfänger zu
+type Publisher struct {
+	db        *database.Database
+	endpoints []config.WebhookEndpoint
+	secrets   map[string]string
+
+	// Client führt die HTTP-Requests aus
+	Client *http.Client
+	// MaxAttempts ist die Anzahl der Versuche pro Zustellung
+	MaxAttempts int
+	// BaseDelay ist die Wartezeit nach dem ersten Fehlversuch, sie verdoppelt sich bis MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// PollInterval ist der Abstand, in dem Run die Warteschlange prüft
+	PollInterval time.Duration
+	// Now liefert die aktuelle Zeit (für Tests austauschbar)
+	Now func() time.Time
+
+	wake chan struct{}
+}
+
+// NewPublisher erstellt einen Publisher für die Empfänger aus der Konfiguration
+func NewPublisher(db *database.Database, cfg config.WebhooksConfig) *Publisher {
+	p := &Publisher{
+		db:           db,
+		endpoints:    cfg.Endpoints,
+		secrets:      make(map[string]string, len(cfg.Endpoints)),
+		Client:       &http.Client{Timeout: requestTimeout},
+		MaxAttempts:  cfg.MaxAttempts,
+		BaseDelay:    defaultBaseDelay,
+		MaxDelay:     defaultMaxDelay,
+		PollInterval: defaultPollInterval,
+		Now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	for _, endpoint := range cfg.Endpoints {
+		p.secrets[endpoint.URL] = endpoint.Secret
+	}
+
+	return p
+}
+
+var _ database.WebhookRouter = (*Publisher)(nil)
+
+// Publish reiht ein Event für alle Empfänger ein, die den Event-Typ abonniert haben.
+// Events zu Änderungen werden stattdessen über database.Database.WithEvent mit der Änderung gespeichert.
+func (p *Publisher) Publish(ctx context.Context, eventType string, data any) error {
+	event, err := p.Route(eventType, data)
+	if err != nil || event == nil {
+		return err
+	}
+
+	if err := p.db.EnqueueWebhook(ctx, event.ID, event.Type, event.Payload, event.URLs); err != nil {
+		return err
+	}
+
+	p.Notify()
+	return nil
+}
+
+// Route serialisiert ein Event für alle Empfänger, die den Event-Typ abonniert haben (nil, wenn keiner)
+func (p *Publisher) Route(eventType string, data any) (*database.QueuedEvent, error) {
+	var urls []string
+	for _, endpoint := range p.endpoints {
+		if endpoint.Wants(eventType) {
+			urls = append(urls, endpoint.URL)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Serialisieren der Event-Daten: %w", err)
+	}
+
+	event := leagueapi.WebhookEvent{
+		ID:        newEventID(),
+		Type:      eventType,
+		CreatedAt: p.Now().UTC(),
+		Data:      raw,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("fehler beim Serialisieren des Events: %w", err)
+	}
+
+	return &database.QueuedEvent{ID: event.ID, Type: eventType, Payload: payload, URLs: urls}, nil
+}
+
+// Notify weckt den Worker, statt auf das nächste Intervall zu warten
+func (p *Publisher) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run stellt fällige Zustellungen zu, bis ctx beendet wird
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.ProcessDue(ctx); err != nil {
+			log.Printf("[Webhooks] %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+// ProcessDue versucht alle fälligen Zustellungen einmal zuzustellen und gibt ihre Anzahl zurück
+func (p *Publisher) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := p.db.GetDueWebhookDeliveries(ctx, p.Now(), defaultBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if err := p.deliver(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver führt einen Zustellversuch aus und speichert das Ergebnis
+func (p *Publisher) deliver(ctx context.Context, delivery *database.WebhookDelivery) error {
+	attempt := &database.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts + 1,
+	}
+
+	start := time.Now()
+	statusCode, err := p.send(ctx, delivery)
+	attempt.Duration = time.Since(start)
+
+	if statusCode != 0 {
+		attempt.StatusCode = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("empfänger antwortete mit status %d", statusCode)
+	}
+
+	status := database.WebhookDelivered
+	nextAttemptAt := p.Now()
+	if err != nil {
+		attempt.Error = sql.NullString{String: err.Error(), Valid: true}
+		status = database.WebhookPending
+		nextAttemptAt = nextAttemptAt.Add(p.backoff(attempt.Attempt))
+		if attempt.Attempt >= p.MaxAttempts {
+			status = database.WebhookFailed
+			log.Printf("[Webhooks] Zustellung %d (%s an %s) nach %d Versuchen aufgegeben: %v",
+				delivery.ID, delivery.EventType, delivery.URL, attempt.Attempt, err)
+		}
+	}
+
+	return p.db.RecordWebhookAttempt(ctx, attempt, status, nextAttemptAt)
+}
+
+// send schickt den signierten Request und gibt den HTTP-Status zurück
+func (p *Publisher) send(ctx context.Context, delivery *database.WebhookDelivery) (int, error) {
+	secret, ok := p.secrets[delivery.URL]
+	if !ok {
+		return 0, fmt.Errorf("empfänger %s ist nicht mehr konfiguriert", delivery.URL)
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("fehler beim Erstellen des Requests: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PrestigeLeague-Webhooks/1.0")
+	req.Header.Set(leagueapi.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(leagueapi.WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(leagueapi.WebhookSignatureHeader, leagueapi.SignWebhook(secret, p.Now(), body))
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// backoff gibt die Wartezeit nach dem n-ten Fehlversuch zurück (exponentiell, begrenzt auf MaxDelay)
+func (p *Publisher) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// newEventID erzeugt eine zufällige Event-ID
+func newEventID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,269 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamie/prestigeleagueseasonfour/internal/config"
+	"github.com/jamie/prestigeleagueseasonfour/internal/database"
+	"github.com/jamie/prestigeleagueseasonfour/internal/webhooks"
+	"github.com/jamie/prestigeleagueseasonfour/pkg/leagueapi"
+)
+
+const secret = "test-secret"
+
+// receiver ist ein lokaler Webhook-Empfänger, der die ersten failures Requests ablehnt
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	events   []leagueapi.WebhookEvent
+	headers  []http.Header
+}
+
+func newReceiver(t *testing.T, failures int) *receiver {
+	t.Helper()
+
+	r := &receiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if err := leagueapi.VerifyWebhook(secret, req.Header.Get(leagueapi.WebhookSignatureHeader), body, 0); err != nil {
+			t.Errorf("VerifyWebhook: %v", err)
+		}
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var event leagueapi.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		r.events = append(r.events, event)
+		r.headers = append(r.headers, req.Header.Clone())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []leagueapi.WebhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]leagueapi.WebhookEvent(nil), r.events...)
+}
+
+// clock ist eine manuell gestellte Uhr für Backoff-Tests
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+func newClock() *clock                   { return &clock{now: time.Now()} }
+
+func openDB(t *testing.T) *database.Database {
+	return openDBAt(t, filepath.Join(t.TempDir(), "league.db"))
+}
+
+func openDBAt(t *testing.T, path string) *database.Database {
+	t.Helper()
+	db, err := database.New(path)
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newPublisher(db *database.Database, c *clock, endpoints ...config.WebhookEndpoint) *webhooks.Publisher {
+	p := webhooks.NewPublisher(db, config.WebhooksConfig{MaxAttempts: 3, Endpoints: endpoints})
+	p.Now = c.Now
+	p.BaseDelay = time.Minute
+	return p
+}
+
+func process(t *testing.T, p *webhooks.Publisher) int {
+	t.Helper()
+	n, err := p.ProcessDue(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+	return n
+}
+
+func TestDeliverSignedEvent(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r := newReceiver(t, 0)
+	p := newPublisher(db, newClock(), config.WebhookEndpoint{URL: r.URL, Secret: secret})
+
+	err := p.Publish(ctx, leagueapi.EventMatchReported, leagueapi.MatchResultEvent{MatchID: 7, ScoreHome: 3, ScoreAway: 1})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if n := process(t, p); n != 1 {
+		t.Fatalf("ProcessDue delivered %d, want 1", n)
+	}
+
+	events := r.received()
+	if len(events) != 1 || events[0].Type != leagueapi.EventMatchReported {
+		t.Fatalf("received %+v", events)
+	}
+	var data leagueapi.MatchResultEvent
+	if err := json.Unmarshal(events[0].Data, &data); err != nil || data.MatchID != 7 || data.ScoreHome != 3 {
+		t.Fatalf("data = %+v, %v", data, err)
+	}
+	if got := r.headers[0].Get(leagueapi.WebhookDeliveryHeader); got != events[0].ID {
+		t.Errorf("delivery header = %q, want %q", got, events[0].ID)
+	}
+
+	deliveries, _ := db.GetWebhookDeliveries(ctx, 10)
+	if len(deliveries) != 1 || deliveries[0].Status != database.WebhookDelivered || !deliveries[0].DeliveredAt.Valid {
+		t.Fatalf("deliveries = %+v", deliveries[0])
+	}
+	attempts, _ := db.GetWebhookAttempts(ctx, deliveries[0].ID)
+	if len(attempts) != 1 || attempts[0].StatusCode.Int64 != http.StatusNoContent {
+		t.Fatalf("attempts = %+v", attempts)
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r := newReceiver(t, 2)
+	c := newClock()
+	p := newPublisher(db, c, config.WebhookEndpoint{URL: r.URL, Secret: secret})
+
+	if err := p.Publish(ctx, leagueapi.EventScheduleGenerated, leagueapi.ScheduleGeneratedEvent{Division: 1}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// 1. Versuch schlägt fehl, nächster Versuch nach BaseDelay
+	process(t, p)
+	if n := process(t, p); n != 0 {
+		t.Fatalf("retry before backoff: %d deliveries", n)
+	}
+	c.Advance(time.Minute)
+
+	// 2. Versuch schlägt fehl, Wartezeit verdoppelt sich
+	process(t, p)
+	c.Advance(time.Minute)
+	if n := process(t, p); n != 0 {
+		t.Fatalf("retry before doubled backoff: %d deliveries", n)
+	}
+	c.Advance(time.Minute)
+
+	// 3. Versuch gelingt
+	if n := process(t, p); n != 1 {
+		t.Fatalf("third attempt: %d deliveries", n)
+	}
+	if len(r.received()) != 1 {
+		t.Fatalf("received %d events, want 1", len(r.received()))
+	}
+
+	deliveries, _ := db.GetWebhookDeliveries(ctx, 10)
+	attempts, _ := db.GetWebhookAttempts(ctx, deliveries[0].ID)
+	if deliveries[0].Status != database.WebhookDelivered || len(attempts) != 3 {
+		t.Fatalf("status = %s, attempts = %d", deliveries[0].Status, len(attempts))
+	}
+	if attempts[0].StatusCode.Int64 != http.StatusServiceUnavailable || !attempts[0].Error.Valid {
+		t.Errorf("first attempt = %+v", attempts[0])
+	}
+}
+
+func TestGiveUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r := newReceiver(t, 100)
+	c := newClock()
+	p := newPublisher(db, c, config.WebhookEndpoint{URL: r.URL, Secret: secret})
+
+	if err := p.Publish(ctx, leagueapi.EventTeamDisqualified, leagueapi.TeamDisqualifiedEvent{}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		process(t, p)
+		c.Advance(time.Hour)
+	}
+
+	deliveries, _ := db.GetWebhookDeliveries(ctx, 10)
+	if deliveries[0].Status != database.WebhookFailed || deliveries[0].Attempts != 3 {
+		t.Fatalf("status = %s, attempts = %d", deliveries[0].Status, deliveries[0].Attempts)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	all := newReceiver(t, 0)
+	filtered := newReceiver(t, 0)
+	p := newPublisher(db, newClock(),
+		config.WebhookEndpoint{URL: all.URL, Secret: secret},
+		config.WebhookEndpoint{URL: filtered.URL, Secret: secret, Events: []string{leagueapi.EventTeamDisqualified}},
+	)
+
+	p.Publish(ctx, leagueapi.EventMatchReported, leagueapi.MatchResultEvent{})
+	p.Publish(ctx, leagueapi.EventTeamDisqualified, leagueapi.TeamDisqualifiedEvent{})
+	process(t, p)
+
+	if n := len(all.received()); n != 2 {
+		t.Errorf("unfiltered endpoint received %d events, want 2", n)
+	}
+	if events := filtered.received(); len(events) != 1 || events[0].Type != leagueapi.EventTeamDisqualified {
+		t.Errorf("filtered endpoint received %+v", events)
+	}
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "league.db")
+	r := newReceiver(t, 0)
+	endpoint := config.WebhookEndpoint{URL: r.URL, Secret: secret}
+
+	// Event einreihen, ohne es zuzustellen
+	first := openDBAt(t, path)
+	if err := newPublisher(first, newClock(), endpoint).Publish(ctx, leagueapi.EventMatchConfirmed, leagueapi.MatchResultEvent{MatchID: 1}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	first.Close()
+
+	// Neuer Prozess stellt das gespeicherte Event zu
+	second := openDBAt(t, path)
+	if n := process(t, newPublisher(second, newClock(), endpoint)); n != 1 {
+		t.Fatalf("ProcessDue after restart delivered %d, want 1", n)
+	}
+	if events := r.received(); len(events) != 1 || events[0].Type != leagueapi.EventMatchConfirmed {
+		t.Fatalf("received %+v", events)
+	}
+}
+
+func TestVerifyWebhookRejectsTampering(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	header := leagueapi.SignWebhook(secret, time.Now(), body)
+
+	if err := leagueapi.VerifyWebhook(secret, header, body, time.Minute); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := leagueapi.VerifyWebhook("other", header, body, time.Minute); err == nil {
+		t.Error("wrong secret accepted")
+	}
+	if err := leagueapi.VerifyWebhook(secret, header, []byte(`{"id":"evt_2"}`), time.Minute); err == nil {
+		t.Error("tampered body accepted")
+	}
+	old := leagueapi.SignWebhook(secret, time.Now().Add(-time.Hour), body)
+	if err := leagueapi.VerifyWebhook(secret, old, body, time.Minute); err == nil {
+		t.Error("expired timestamp accepted")
+	}
+}
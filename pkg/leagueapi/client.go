@@ -0,0 +1,198 @@
+// Package leagueapi enthält die OpenAPI-Spezifikation, die JSON-Typen und einen
+// Go-Client für die Liga-API (/api/v1). Externe Tools wie Stream-Overlays oder
+// Statistikseiten können das Paket direkt importieren.
+package leagueapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Spec ist das OpenAPI 3 Dokument der Liga-API
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// DefaultBaseURL ist die öffentliche Adresse der Liga-API
+const DefaultBaseURL = "https://prestigeleague.de/api/v1"
+
+// APIError ist ein Fehler, den die API mit einem JSON-Body beantwortet hat
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Client greift auf die Liga-API zu
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient erstellt einen Client für die angegebene Basis-URL (inklusive /api/v1).
+// Eine leere URL verwendet DefaultBaseURL.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// ListOptions steuert die Paginierung von Listen. Nullwerte verwenden die Standardwerte der API.
+type ListOptions struct {
+	Page    int
+	PerPage int
+}
+
+// TeamFilter filtert GET /teams
+type TeamFilter struct {
+	ListOptions
+	Division int
+}
+
+// MatchFilter filtert GET /matches
+type MatchFilter struct {
+	ListOptions
+	Division int
+	Matchday int
+	TeamID   int
+}
+
+// Divisions liefert alle Divisionen
+func (c *Client) Divisions(ctx context.Context) ([]Division, error) {
+	var divisions []Division
+	err := c.get(ctx, "/divisions", nil, &divisions)
+	return divisions, err
+}
+
+// DivisionTeams liefert die Teams einer Division
+func (c *Client) DivisionTeams(ctx context.Context, division int) ([]Team, error) {
+	var teams []Team
+	err := c.get(ctx, fmt.Sprintf("/divisions/%d/teams", division), nil, &teams)
+	return teams, err
+}
+
+// Standings liefert die Tabelle einer Division
+func (c *Client) Standings(ctx context.Context, division int) ([]Standing, error) {
+	var rows []Standing
+	err := c.get(ctx, fmt.Sprintf("/divisions/%d/standings", division), nil, &rows)
+	return rows, err
+}
+
+// DivisionMatches liefert eine Seite der Matches einer Division
+func (c *Client) DivisionMatches(ctx context.Context, division int, opts ListOptions) (*Page[Match], error) {
+	var page Page[Match]
+	err := c.get(ctx, fmt.Sprintf("/divisions/%d/matches", division), opts.values(), &page)
+	return &page, err
+}
+
+// Teams liefert eine Seite aller Teams
+func (c *Client) Teams(ctx context.Context, filter TeamFilter) (*Page[Team], error) {
+	query := filter.values()
+	setInt(query, "division", filter.Division)
+
+	var page Page[Team]
+	err := c.get(ctx, "/teams", query, &page)
+	return &page, err
+}
+
+// Team liefert ein Team mit Roster, Tabellenplatz und Matches
+func (c *Client) Team(ctx context.Context, id int) (*TeamDetail, error) {
+	var team TeamDetail
+	if err := c.get(ctx, fmt.Sprintf("/teams/%d", id), nil, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// Roster liefert die Spieler eines Teams
+func (c *Client) Roster(ctx context.Context, teamID int) ([]Player, error) {
+	var players []Player
+	err := c.get(ctx, fmt.Sprintf("/teams/%d/roster", teamID), nil, &players)
+	return players, err
+}
+
+// Matches liefert eine Seite der Matches, optional gefiltert
+func (c *Client) Matches(ctx context.Context, filter MatchFilter) (*Page[Match], error) {
+	query := filter.values()
+	setInt(query, "division", filter.Division)
+	setInt(query, "matchday", filter.Matchday)
+	setInt(query, "team", filter.TeamID)
+
+	var page Page[Match]
+	err := c.get(ctx, "/matches", query, &page)
+	return &page, err
+}
+
+// Match liefert ein einzelnes Match
+func (c *Client) Match(ctx context.Context, id int) (*Match, error) {
+	var match Match
+	if err := c.get(ctx, fmt.Sprintf("/matches/%d", id), nil, &match); err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// get führt einen GET-Request aus und dekodiert die JSON-Antwort in out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("fehler beim Erstellen des Requests: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fehler beim Abrufen von %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var body Error
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("fehler beim Dekodieren von %s: %w", path, err)
+	}
+	return nil
+}
+
+func (o ListOptions) values() url.Values {
+	query := url.Values{}
+	setInt(query, "page", o.Page)
+	setInt(query, "per_page", o.PerPage)
+	return query
+}
+
+func setInt(query url.Values, name string, value int) {
+	if value > 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
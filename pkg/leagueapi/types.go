@@ -0,0 +1,84 @@
+package leagueapi
+
+import "time"
+
+// Division ist die JSON-Darstellung einer Division
+type Division struct {
+	Division  int `json:"division"`
+	TeamCount int `json:"team_count"`
+}
+
+// Team ist die JSON-Darstellung eines Teams
+type Team struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Division       int    `json:"division"`
+	IsDisqualified bool   `json:"is_disqualified"`
+	IsWithdrawn    bool   `json:"is_withdrawn"`
+}
+
+// Player ist die JSON-Darstellung eines Spielers
+type Player struct {
+	Name       string `json:"name"`
+	TrackerURL string `json:"tracker_url,omitempty"`
+}
+
+// Match ist die JSON-Darstellung eines Matches
+type Match struct {
+	ID         int        `json:"id"`
+	Division   int        `json:"division"`
+	Matchday   int        `json:"matchday"`
+	HomeTeamID *int       `json:"home_team_id"`
+	HomeTeam   *string    `json:"home_team"`
+	AwayTeamID *int       `json:"away_team_id"`
+	AwayTeam   *string    `json:"away_team"`
+	ScoreHome  *int       `json:"score_home"`
+	ScoreAway  *int       `json:"score_away"`
+	IsBye      bool       `json:"is_bye"`
+	Completed  bool       `json:"completed"`
+	ReportedAt *time.Time `json:"reported_at"`
+	// ScheduledAt ist der zwischen den Teams vereinbarte Spieltermin
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// Standing ist eine Zeile der Tabelle
+type Standing struct {
+	Position       int    `json:"position"`
+	TeamID         int    `json:"team_id"`
+	Name           string `json:"name"`
+	IsDisqualified bool   `json:"is_disqualified"`
+	IsWithdrawn    bool   `json:"is_withdrawn"`
+	Played         int    `json:"played"`
+	Wins           int    `json:"wins"`
+	Losses         int    `json:"losses"`
+	GamesWon       int    `json:"games_won"`
+	GamesLost      int    `json:"games_lost"`
+	GameDiff       int    `json:"game_diff"`
+	Points         int    `json:"points"`
+}
+
+// TeamDetail fasst Team, Roster, Tabellenplatz und Matches zusammen
+type TeamDetail struct {
+	Team
+	Roster   []Player  `json:"roster"`
+	Standing *Standing `json:"standing"`
+	Matches  []Match   `json:"matches"`
+}
+
+// Page ist die Hülle für paginierte Listen
+type Page[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination beschreibt die aktuelle Seite einer Liste
+type Pagination struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	Total   int `json:"total"`
+}
+
+// Error ist die JSON-Darstellung eines Fehlers
+type Error struct {
+	Error string `json:"error"`
+}
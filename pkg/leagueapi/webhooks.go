@@ -0,0 +1,119 @@
+package leagueapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event-Typen der ausgehenden Webhooks
+const (
+	// EventMatchReported wird gesendet, wenn ein Team ein Ergebnis mit /report_result einträgt
+	EventMatchReported = "match.reported"
+	// EventMatchConfirmed wird gesendet, sobald ein Ergebnis gilt: nach /report_result (zusammen
+	// mit match.reported) und wenn die Liga-Leitung ein Ergebnis mit /set_result festlegt
+	EventMatchConfirmed = "match.confirmed"
+	// EventTeamDisqualified wird gesendet, wenn ein Team disqualifiziert wird
+	EventTeamDisqualified = "team.disqualified"
+	// EventScheduleGenerated wird gesendet, wenn ein Spielplan erstellt oder neu generiert wird
+	EventScheduleGenerated = "schedule.generated"
+)
+
+// Header der Webhook-Requests
+const (
+	// WebhookSignatureHeader enthält Zeitstempel und HMAC-Signatur: "t=<unix>,v1=<hex>"
+	WebhookSignatureHeader = "X-League-Signature"
+	// WebhookEventHeader enthält den Event-Typ
+	WebhookEventHeader = "X-League-Event"
+	// WebhookDeliveryHeader enthält die Event-ID (bei Wiederholungen gleich)
+	WebhookDeliveryHeader = "X-League-Delivery"
+)
+
+// WebhookEvent ist der JSON-Body jedes Webhook-Requests
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// EventTeam ist ein Team in den Event-Daten
+type EventTeam struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// MatchResultEvent sind die Daten von match.reported und match.confirmed
+type MatchResultEvent struct {
+	MatchID    int        `json:"match_id"`
+	Division   int        `json:"division"`
+	Matchday   int        `json:"matchday"`
+	HomeTeam   EventTeam  `json:"home_team"`
+	AwayTeam   *EventTeam `json:"away_team"`
+	ScoreHome  int        `json:"score_home"`
+	ScoreAway  int        `json:"score_away"`
+	ReportedBy string     `json:"reported_by"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// TeamDisqualifiedEvent sind die Daten von team.disqualified
+type TeamDisqualifiedEvent struct {
+	Team Team `json:"team"`
+}
+
+// ScheduleGeneratedEvent sind die Daten von schedule.generated
+type ScheduleGeneratedEvent struct {
+	Division     int    `json:"division"`
+	Mode         string `json:"mode"`
+	FromMatchday int    `json:"from_matchday"`
+	Matchdays    int    `json:"matchdays"`
+	Matches      int    `json:"matches"`
+}
+
+// SignWebhook berechnet die Signatur eines Webhook-Bodys: HMAC-SHA256 über "<timestamp>.<body>"
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook prüft den Signatur-Header eines empfangenen Webhooks.
+// tolerance begrenzt das Alter des Requests (0 = keine Prüfung).
+func VerifyWebhook(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signature = value
+		}
+	}
+	if ts == "" || signature == "" {
+		return errors.New("signatur-header unvollständig")
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("ungültiger zeitstempel: %w", err)
+	}
+	timestamp := time.Unix(unix, 0)
+	if tolerance > 0 && time.Since(timestamp).Abs() > tolerance {
+		return errors.New("zeitstempel außerhalb der toleranz")
+	}
+
+	expected := SignWebhook(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte("t="+ts+",v1="+signature)) {
+		return errors.New("signatur ungültig")
+	}
+	return nil
+}